@@ -0,0 +1,271 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"expvar"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/logger"
+)
+
+const adminAddrFlag = "admin-addr"
+
+var processStartTime = time.Now()
+
+// MetricsHandler is an optional interface for components exposing metrics.
+// The first one found serves /metrics of the admin server, otherwise it serves
+// Go runtime metrics in Prometheus text format
+type MetricsHandler interface {
+	MetricsHandler() http.Handler
+}
+
+// adminServer is a private HTTP server for operators, it is enabled by
+// the admin-addr flag and mounts:
+//   - /debug/pprof/: profiles of net/http/pprof
+//   - /debug/vars: expvar
+//   - /metrics: see MetricsHandler
+//   - /livez, /healthz, /readyz: health endpoints
+//   - /debug/config: the effective config with masked secrets
+//   - /debug/log-levels: GET levels, PUT {"prefix": "gin", "level": "debug"} to change one
+//   - /debug/components: components with their state
+type adminServer struct {
+	sv     *service
+	addr   string
+	logger logger.Logger
+
+	mu          sync.Mutex
+	svr         *http.Server
+	listenAddr  string
+	started     chan struct{}
+	startedOnce sync.Once
+	// set by Stop, so Run doesn't serve after it
+	stopped bool
+}
+
+func newAdminServer(sv *service) *adminServer {
+	return &adminServer{sv: sv, started: make(chan struct{})}
+}
+
+func (a *adminServer) Name() string {
+	return a.sv.name + "-admin"
+}
+
+func (a *adminServer) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.addr, adminAddrFlag, "", "Address of the admin server with pprof, metrics, health, "+
+		"log levels and component states. Ex: 127.0.0.1:9090, disabled if empty")
+}
+
+func (a *adminServer) Configure() error {
+	a.logger = logger.GetCurrent().GetLogger("admin")
+	return nil
+}
+
+func (a *adminServer) Run() error {
+	defer a.setStarted()
+
+	if a.addr == "" {
+		return nil
+	}
+
+	if err := a.Configure(); err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("admin server failed to listen: %w", err)
+	}
+
+	svr := &http.Server{Handler: a.router(), ReadHeaderTimeout: 10 * time.Second}
+
+	a.mu.Lock()
+	// Stop was called before Run, ex: another component failed to start
+	if a.stopped {
+		a.mu.Unlock()
+		return lis.Close()
+	}
+	a.svr, a.listenAddr = svr, lis.Addr().String()
+	a.mu.Unlock()
+	a.setStarted()
+
+	a.logger.Infof("admin server listens on %s...", lis.Addr().String())
+
+	if err := svr.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (a *adminServer) setStarted() {
+	a.startedOnce.Do(func() { close(a.started) })
+}
+
+// Addr blocks until the server listens and returns its address, empty if it is disabled
+func (a *adminServer) Addr() string {
+	<-a.started
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.listenAddr
+}
+
+// Stop closes the server, it is stopped with other components
+// after the public servers are drained
+func (a *adminServer) Stop() <-chan bool {
+	a.mu.Lock()
+	svr := a.svr
+	a.stopped = true
+	a.mu.Unlock()
+
+	c := make(chan bool)
+
+	go func() {
+		if svr != nil {
+			_ = svr.Close()
+		}
+		c <- true
+	}()
+
+	return c
+}
+
+func (a *adminServer) router() http.Handler {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+
+	engine.GET("/debug/pprof/*name", gin.WrapF(pprofHandler))
+	engine.POST("/debug/pprof/*name", gin.WrapF(pprofHandler))
+	engine.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	engine.GET("/metrics", gin.WrapH(a.metricsHandler()))
+
+	a.sv.healthHandler(engine)
+
+	engine.GET("/debug/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, a.sv.configDump())
+	})
+
+	engine.GET("/debug/log-levels", a.getLogLevels)
+	engine.PUT("/debug/log-levels", a.setLogLevel)
+
+	engine.GET("/debug/components", func(c *gin.Context) {
+		c.JSON(http.StatusOK, a.sv.componentStatuses())
+	})
+
+	return engine
+}
+
+func pprofHandler(w http.ResponseWriter, r *http.Request) {
+	switch name := strings.TrimPrefix(r.URL.Path, "/debug/pprof/"); name {
+	case "", "/debug/pprof":
+		pprof.Index(w, r)
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Handler(name).ServeHTTP(w, r)
+	}
+}
+
+// metricsHandler returns the handler of the first component implementing MetricsHandler
+func (a *adminServer) metricsHandler() http.Handler {
+	for _, pre := range a.sv.initOrder {
+		if m, ok := unwrapComponent(a.sv.initServices[pre]).(MetricsHandler); ok {
+			return m.MetricsHandler()
+		}
+	}
+
+	for _, subService := range a.sv.subServices {
+		if m, ok := unwrapComponent(subService).(MetricsHandler); ok {
+			return m.MetricsHandler()
+		}
+	}
+
+	return http.HandlerFunc(a.runtimeMetrics)
+}
+
+// runtimeMetrics writes Go runtime metrics in Prometheus text format
+func (a *adminServer) runtimeMetrics(w http.ResponseWriter, _ *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	ready := 0
+	if a.sv.isReady() {
+		ready = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	metrics := []struct {
+		name, help, kind string
+		value            interface{}
+	}{
+		{"go_goroutines", "Number of goroutines that currently exist.", "gauge", runtime.NumGoroutine()},
+		{"go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", "gauge", mem.Alloc},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from system.", "gauge", mem.Sys},
+		{"go_memstats_heap_objects", "Number of allocated objects.", "gauge", mem.HeapObjects},
+		{"go_gc_cycles_total", "Number of completed GC cycles.", "counter", mem.NumGC},
+		{"process_start_time_seconds", "Start time of the process since unix epoch in seconds.", "gauge", processStartTime.Unix()},
+		{"service_ready", "Whether the service is ready to serve traffic.", "gauge", ready},
+	}
+
+	for _, m := range metrics {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+
+	_, _ = fmt.Fprintf(w, "# HELP service_info Name, version and env of the service.\n# TYPE service_info gauge\n"+
+		"service_info{name=%q,version=%q,env=%q} 1\n", a.sv.name, a.sv.version, a.sv.env)
+}
+
+type logLevelRequest struct {
+	Prefix string `json:"prefix"`
+	Level  string `json:"level"`
+}
+
+func (a *adminServer) getLogLevels(c *gin.Context) {
+	lc, ok := logger.GetCurrent().(logger.LevelController)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the logger does not support log levels by prefix"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lc.Levels())
+}
+
+// setLogLevel sets the level of a prefix, the default one if prefix is empty.
+// An empty level removes the level of prefix
+func (a *adminServer) setLogLevel(c *gin.Context) {
+	lc, ok := logger.GetCurrent().(logger.LevelController)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the logger does not support log levels by prefix"})
+		return
+	}
+
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := lc.SetLevel(req.Prefix, req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a.logger.Infof("log level of %q is set to %q", req.Prefix, req.Level)
+	c.JSON(http.StatusOK, lc.Levels())
+}
@@ -0,0 +1,137 @@
+package goservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminServer(t *testing.T) {
+	ready := make(chan struct{})
+
+	sv := New(
+		WithName("demo"),
+		WithArgs("-ginPort=0", "-admin-addr=127.0.0.1:0"),
+		WithInitRunnable(&testComponent{prefix: "gorm"}),
+		WithOnReady(func(sc ServiceContext) error {
+			close(ready)
+			return nil
+		}),
+	).(*service)
+	require.NoError(t, sv.Init())
+	sv.HTTPServer().AddHandler(func(engine *gin.Engine) {})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- sv.Start(nil) }()
+	<-ready
+
+	base := "http://" + sv.admin.Addr()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(base + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("/debug/pprof/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "goroutine")
+
+	code, _ = get("/debug/pprof/heap")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body = get("/debug/vars")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "memstats")
+
+	_, body = get("/metrics")
+	assert.Contains(t, body, "go_goroutines ")
+	assert.Contains(t, body, "service_ready 1")
+	assert.Contains(t, body, `service_info{name="demo"`)
+
+	code, _ = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body = get("/debug/config")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"name":"demo"`)
+
+	var components []ComponentStatus
+	_, body = get("/debug/components")
+	require.NoError(t, json.Unmarshal([]byte(body), &components))
+	require.Len(t, components, 3)
+	assert.Equal(t, ComponentStatus{Name: "gorm", Kind: "init", State: "running"},
+		ComponentStatus{Name: components[0].Name, Kind: components[0].Kind, State: components[0].State})
+	assert.Equal(t, "demo-gin", components[1].Name)
+	assert.Equal(t, "running", components[1].State)
+
+	req, _ := http.NewRequest(http.MethodPut, base+"/debug/log-levels", strings.NewReader(`{"prefix":"gin","level":"debug"}`))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "debug", logger.GetCurrent().GetLogger("gin").GetLevel())
+
+	req, _ = http.NewRequest(http.MethodPut, base+"/debug/log-levels", strings.NewReader(`{"prefix":"gin","level":"loud"}`))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, body = get("/debug/log-levels")
+	assert.Contains(t, body, `"gin":"debug"`)
+	require.NoError(t, logger.GetCurrent().(logger.LevelController).SetLevel("gin", ""))
+
+	// the public server does not expose admin endpoints
+	gs := sv.httpServer.(interface{ Port() int })
+	resp, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/debug/pprof/", gs.Port()))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	sv.Stop()
+	assert.NoError(t, <-errChan)
+
+	for _, c := range sv.componentStatuses() {
+		assert.Equal(t, "stopped", c.State, c.Name)
+	}
+}
+
+func TestAdminServerDisabled(t *testing.T) {
+	sv := New(WithName("demo")).(*service)
+
+	assert.NoError(t, sv.admin.Run())
+	assert.Empty(t, sv.admin.Addr())
+	<-sv.admin.Stop()
+}
+
+func TestAdminServerStopBeforeRun(t *testing.T) {
+	sv := New(WithName("demo")).(*service)
+	sv.admin.addr = "127.0.0.1:0"
+
+	<-sv.admin.Stop()
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- sv.admin.Run() }()
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		<-sv.admin.Stop()
+		t.Fatal("the admin server serves after Stop")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+// Package cli routes subcommands of a service binary:
+//
+//	app := cli.New(service, cli.WithSQLMigration("", "./migrations"))
+//	app.Execute()
+//
+// Built-in commands are serve (default), outenv, config, migrate and healthcheck.
+// Services can add their own with WithCommand.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	goservice "github.com/lequocbinh04/go-sdk"
+)
+
+const defaultCommand = "serve"
+
+// Command is a subcommand added by the service
+type Command struct {
+	Name string
+	// Usage is a one line description
+	Usage string
+	// Run gets the service with all init components running,
+	// they are stopped when Run returns
+	Run func(sc goservice.ServiceContext, args []string) error
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+type App struct {
+	service  goservice.Service
+	commands map[string]command
+	out      io.Writer
+	// SQL migration run by migrate command
+	migrationPrefix string
+	migrationFolder string
+}
+
+type Option func(*App)
+
+func New(sv goservice.Service, opts ...Option) *App {
+	app := &App{
+		service:  sv,
+		commands: map[string]command{},
+		out:      os.Stdout,
+	}
+
+	app.addBuiltinCommands()
+
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	return app
+}
+
+// WithCommand adds a command, it replaces the built-in one with the same name
+func WithCommand(cmd Command) Option {
+	return func(app *App) {
+		app.commands[cmd.Name] = command{
+			name:  cmd.Name,
+			usage: cmd.Usage,
+			run:   func(args []string) error { return app.runCommand(cmd, args) },
+		}
+	}
+}
+
+// WithSQLMigration enables migrate command with SQL files in folder
+// for the gorm component with prefix, see dbmigration
+func WithSQLMigration(prefix, folder string) Option {
+	return func(app *App) {
+		app.migrationPrefix = prefix
+		app.migrationFolder = folder
+	}
+}
+
+// WithOutput sets where commands print, default: stdout
+func WithOutput(w io.Writer) Option {
+	return func(app *App) { app.out = w }
+}
+
+// Run runs the command named by the first argument with the other ones.
+// Without arguments, it runs serve
+func (app *App) Run(args []string) error {
+	name := defaultCommand
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "help", "-h", "-help", "--help":
+		app.printUsage()
+		return nil
+	}
+
+	cmd, ok := app.commands[name]
+	if !ok {
+		app.printUsage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	return cmd.run(args)
+}
+
+// Execute runs the command of os.Args and exits with status 1 if it fails
+func (app *App) Execute() {
+	if err := app.Run(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func (app *App) runCommand(cmd Command, args []string) error {
+	if err := app.service.Init(); err != nil {
+		return err
+	}
+	defer app.service.Stop()
+
+	return cmd.Run(app.service, args)
+}
+
+func (app *App) printUsage() {
+	names := make([]string, 0, len(app.commands))
+	for name := range app.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintf(app.out, "Usage of %s:\n  %s <command> [arguments]\n\nCommands:\n", app.service.Name(), os.Args[0])
+	for _, name := range names {
+		_, _ = fmt.Fprintf(app.out, "  %-12s %s\n", name, app.commands[name].usage)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	goservice "github.com/lequocbinh04/go-sdk"
+	"github.com/lequocbinh04/go-sdk/servicetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	sv := servicetest.Start(t, goservice.WithName("demo"))
+	app := New(sv.Service)
+
+	assert.NoError(t, app.Run([]string{"healthcheck"}))
+	assert.NoError(t, app.Run([]string{"healthcheck", "-url", sv.URL("/readyz")}))
+	assert.Error(t, app.Run([]string{"healthcheck", "-url", sv.URL("/not-found")}))
+}
+
+func TestHealthCheckTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, ca, 0o644))
+
+	app := New(servicetest.New(t).Service)
+	url := srv.URL + "/readyz"
+
+	assert.Error(t, app.Run([]string{"healthcheck", "-url", url}), "the certificate is verified by default")
+	assert.NoError(t, app.Run([]string{"healthcheck", "-url", url, "-insecure"}))
+	assert.NoError(t, app.Run([]string{"healthcheck", "-url", url, "-ca-file", caFile}))
+	assert.NoError(t, app.Run([]string{"healthcheck", "-url", url, "-ca-file", caFile, "-server-name", "example.com"}))
+	assert.Error(t, app.Run([]string{"healthcheck", "-url", url, "-ca-file", caFile, "-server-name", "other.com"}))
+}
+
+func TestCommand(t *testing.T) {
+	sv := servicetest.New(t, goservice.WithName("demo"), servicetest.Config("app-env", goservice.StgEnv))
+
+	var env string
+	var args []string
+
+	app := New(sv.Service, WithCommand(Command{
+		Name: "hello",
+		Run: func(sc goservice.ServiceContext, a []string) error {
+			env, args = sc.Env(), a
+			return nil
+		},
+	}))
+
+	assert.NoError(t, app.Run([]string{"hello", "world"}))
+	assert.Equal(t, goservice.StgEnv, env)
+	assert.Equal(t, []string{"world"}, args)
+	assert.Error(t, app.Run([]string{"unknown"}))
+}
+
+func TestServe(t *testing.T) {
+	ready := make(chan string, 1)
+	sv := goservice.New(goservice.WithName("demo"), goservice.WithOnReady(func(sc goservice.ServiceContext) error {
+		ready <- sc.Env()
+		return nil
+	}))
+	app := New(sv)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- app.Run([]string{"serve", "-app-env=" + goservice.StgEnv}) }()
+
+	select {
+	case env := <-ready:
+		assert.Equal(t, goservice.StgEnv, env)
+	case err := <-errChan:
+		t.Fatalf("serve: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("service is not ready")
+	}
+
+	sv.Stop()
+	assert.NoError(t, <-errChan)
+
+	assert.Error(t, New(goservice.New(goservice.WithName("demo"))).Run([]string{"serve", "now"}))
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"1.sql":      "CREATE TABLE configs (name TEXT, value TEXT); INSERT INTO configs VALUES ('DB_VERSION', '0');",
+		"2.sql":      "CREATE TABLE notes (id INTEGER);",
+		"2.down.sql": "DROP TABLE notes;",
+		// the env file is rolled back before the base one, while notes exists
+		"2.dev.sql":      "INSERT INTO notes VALUES (1);",
+		"2.dev.down.sql": "DELETE FROM notes;",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	out := &bytes.Buffer{}
+	// migrate doesn't start the pubsub, so it must not stop it
+	sv := goservice.New(goservice.WithName("demo"), servicetest.SQLite("main", ""), servicetest.LocalPubSub("pubsub"))
+	app := New(sv, WithSQLMigration("", dir), WithOutput(out))
+
+	assert.NoError(t, app.Run([]string{"migrate", "up"}))
+	assert.NoError(t, app.Run([]string{"migrate", "status"}))
+	assert.Equal(t, "current: 2\nlatest: 2\npending: []\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, app.Run([]string{"migrate", "down"}))
+	assert.NoError(t, app.Run([]string{"migrate", "status"}))
+	assert.Equal(t, "current: 1\nlatest: 2\npending: [2]\n", out.String())
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	goservice "github.com/lequocbinh04/go-sdk"
+	"github.com/lequocbinh04/go-sdk/httpserver"
+	"github.com/lequocbinh04/go-sdk/util/dbmigration"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHealthCheckTimeout = 3 * time.Second
+	readinessPath             = "/readyz"
+)
+
+func (app *App) addBuiltinCommands() {
+	for _, cmd := range []command{
+		{name: "serve", usage: "Start the service (default): serve [-flag=value...]", run: app.serve},
+		{name: "outenv", usage: "Print sample env variables, or yaml config with -format=yaml", run: app.outEnv},
+		{name: "config", usage: "Print the effective config with masked secrets", run: app.config},
+		{name: "migrate", usage: "Run SQL migrations: migrate up | down [-steps=1] | status", run: app.migrate},
+		{name: "healthcheck", usage: "Exit with status 1 if the running service is not ready", run: app.healthCheck},
+	} {
+		app.commands[cmd.name] = cmd
+	}
+}
+
+// serve sets flags of the service from args, they win over env variables and config files
+func (app *App) serve(args []string) error {
+	fs := app.service.Flags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("serve takes flags only, got %q", fs.Args())
+	}
+
+	if err := app.service.Init(); err != nil {
+		return err
+	}
+	return app.service.Start(nil)
+}
+
+func (app *App) outEnv(args []string) error {
+	fs := flag.NewFlagSet("outenv", flag.ContinueOnError)
+	format := fs.String("format", app.service.Flags().Lookup("outenv-format").Value.String(), "Format: env | yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := app.service.Flags().Set("outenv-format", *format); err != nil {
+		return err
+	}
+
+	app.service.OutEnv()
+	return nil
+}
+
+func (app *App) config(args []string) error {
+	enc := json.NewEncoder(app.out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(map[string]interface{}{
+		"name":    app.service.Name(),
+		"version": app.service.Version(),
+		"env":     app.service.Env(),
+		"config":  app.service.Flags().Config(),
+	})
+}
+
+func (app *App) migrate(args []string) error {
+	if app.migrationFolder == "" {
+		return errors.New("migrate is not enabled, see cli.WithSQLMigration")
+	}
+
+	if len(args) == 0 {
+		return errors.New("migrate needs a sub command: up | down | status")
+	}
+
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("migrate "+action, flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "Number of versions to roll back")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := app.service.InitPrefix(app.migrationPrefix); err != nil {
+		return err
+	}
+	// other components are not started, Stop would stop them too
+	defer func() { _ = app.service.StopPrefix(app.migrationPrefix) }()
+
+	// ErrComponentNotConfigured if the database has no uri
+	db, err := goservice.Get[*gorm.DB](app.service, app.migrationPrefix)
+	if err != nil {
+		return err
+	}
+
+	migration := dbmigration.NewSQLMigration(db, app.migrationFolder, app.service.Logger("migration"),
+		dbmigration.WithEnv(app.service.Env()))
+
+	switch action {
+	case "up":
+		return migration.Migrate()
+	case "down":
+		return migration.Down(*steps)
+	case "status":
+		status, err := migration.Status()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(app.out, "current: %d\nlatest: %d\npending: %v\n", status.Current, status.Latest, status.Pending)
+		return err
+	}
+
+	return fmt.Errorf("unknown migrate command %q", action)
+}
+
+func (app *App) healthCheck(args []string) error {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	url := fs.String("url", "", "Readiness URL. Default: "+readinessPath+" of the HTTP server")
+	timeout := fs.Duration("timeout", defaultHealthCheckTimeout, "Request timeout")
+	caFile := fs.String("ca-file", "", "CA certificate file verifying the HTTPS server. Default: system CAs")
+	serverName := fs.String("server-name", "",
+		"Host name of the server certificate, if the URL targets an address it is not issued for")
+	insecure := fs.Bool("insecure", false, "Don't verify the certificate of the HTTPS server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *url == "" {
+		*url = app.readinessURL()
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	if strings.HasPrefix(*url, "https://") {
+		tlsCfg := &tls.Config{ServerName: *serverName, InsecureSkipVerify: *insecure, MinVersion: tls.VersionTLS12}
+
+		if *caFile != "" {
+			ca, err := os.ReadFile(*caFile)
+			if err != nil {
+				return err
+			}
+
+			tlsCfg.RootCAs = x509.NewCertPool()
+			if !tlsCfg.RootCAs.AppendCertsFromPEM(ca) {
+				return fmt.Errorf("no certificate in %s", *caFile)
+			}
+		}
+
+		client.Transport = &http.Transport{TLSClientConfig: tlsCfg}
+	}
+
+	resp, err := client.Get(*url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("service is not ready: %s %s", resp.Status, body)
+	}
+
+	return nil
+}
+
+// readinessURL returns the readiness URL of the HTTP server configured by flags.
+// Services listening on unix sockets or inherited sockets only must pass -url
+func (app *App) readinessURL() string {
+	host, port := "127.0.0.1", "3000"
+
+	if f := app.service.Flags().Lookup("ginaddr"); f != nil {
+		switch addr := f.Value.String(); addr {
+		case "", "0.0.0.0", "::":
+		default:
+			host = addr
+		}
+	}
+
+	if f := app.service.Flags().Lookup("ginPort"); f != nil {
+		port = f.Value.String()
+	}
+
+	// the first TCP address of gin-listen replaces ginaddr:ginPort
+	if f := app.service.Flags().Lookup("gin-listen"); f != nil && f.Value.String() != "" {
+		addrs, _ := httpserver.ParseListenAddrs(f.Value.String())
+		for _, addr := range addrs {
+			if addr.Network != "tcp" {
+				continue
+			}
+
+			h, p, _ := net.SplitHostPort(addr.Address)
+			host, port = "127.0.0.1", p
+			if h != "" && h != "0.0.0.0" && h != "::" {
+				host = h
+			}
+			break
+		}
+	}
+
+	scheme := "http"
+	if f := app.service.Flags().Lookup("gin-tls-cert-file"); f != nil && f.Value.String() != "" {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, port), readinessPath)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrComponentNotFound is returned when no component is registered with a prefix
+	ErrComponentNotFound = errors.New("component not found")
+	// ErrComponentType is returned when a component is not of the expected type
+	ErrComponentType = errors.New("component has unexpected type")
+	// ErrComponentNotConfigured is returned when a component has no value,
+	// Ex: sdkgorm without database uri
+	ErrComponentNotConfigured = errors.New("component is not configured")
+)
+
+// Get returns the component with prefix as a T.
+// Ex: db, err := goservice.Get[*gorm.DB](sc, "gorm")
+func Get[T any](sc ServiceContext, prefix string) (T, error) {
+	var zero T
+
+	c, ok := sc.Get(prefix)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrComponentNotFound, prefix)
+	}
+
+	if isNil(c) {
+		return zero, fmt.Errorf("%w: %q", ErrComponentNotConfigured, prefix)
+	}
+
+	v, ok := c.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q is %T, not %s", ErrComponentType, prefix, c, typeName[T]())
+	}
+
+	return v, nil
+}
+
+// MustGet is like Get but panics if the component is missing or has another type
+func MustGet[T any](sc ServiceContext, prefix string) T {
+	v, err := Get[T](sc, prefix)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// isNil reports whether c is nil or a nil pointer, map, slice... in an interface.
+// Ex: sdkredis returns a nil *redis.Client if it has no uri
+func isNil(c interface{}) bool {
+	if c == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// Requirement is a component the service needs, see Require
+type Requirement struct {
+	prefix string
+	check  func(sc ServiceContext) error
+}
+
+// Require declares that the service needs a component with prefix of type T
+func Require[T any](prefix string) Requirement {
+	return Requirement{
+		prefix: prefix,
+		check: func(sc ServiceContext) error {
+			_, err := Get[T](sc, prefix)
+			return err
+		},
+	}
+}
+
+// WithRequirements declares components the service needs.
+// Init fails if any of them is missing or has another type,
+// instead of failing on the first request using them
+func WithRequirements(reqs ...Requirement) Option {
+	return func(s *service) { s.requirements = append(s.requirements, reqs...) }
+}
+
+// checkPrefixRequirements checks all required components are registered
+func (sv *service) checkPrefixRequirements() error {
+	var missing []string
+
+	for _, req := range sv.requirements {
+		if _, ok := sv.initServices[req.prefix]; !ok {
+			missing = append(missing, fmt.Sprintf("%q", req.prefix))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrComponentNotFound, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// checkRequirements checks all required components have the expected type,
+// it must be called after init components run
+func (sv *service) checkRequirements() error {
+	var msgs []string
+
+	for _, req := range sv.requirements {
+		if err := req.check(sv); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+
+	if len(msgs) > 0 {
+		return fmt.Errorf("required components are not satisfied: %s", strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
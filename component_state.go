@@ -0,0 +1,110 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"time"
+)
+
+const (
+	statePending  = "pending"
+	stateRunning  = "running"
+	stateFailed   = "failed"
+	stateStopping = "stopping"
+	stateStopped  = "stopped"
+
+	componentKindInit     = "init"
+	componentKindRunnable = "runnable"
+)
+
+// ComponentStatus is the lifecycle state of a component
+type ComponentStatus struct {
+	// prefix of init components, name of the others
+	Name string `json:"name"`
+	// init | runnable
+	Kind string `json:"kind"`
+	// pending | running | failed | stopping | stopped
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+	Since time.Time `json:"since,omitempty"`
+}
+
+func (sv *service) setState(name, state string, err error) {
+	sv.statesMu.Lock()
+	defer sv.statesMu.Unlock()
+
+	if sv.states == nil {
+		sv.states = map[string]ComponentStatus{}
+	}
+
+	st := ComponentStatus{Name: name, State: state, Since: time.Now()}
+	if err != nil {
+		st.Error = err.Error()
+	}
+	sv.states[name] = st
+}
+
+// runComponent runs c and records its state
+func (sv *service) runComponent(name string, c Runnable) error {
+	err := c.Run()
+	if err != nil {
+		sv.setState(name, stateFailed, err)
+		return err
+	}
+
+	sv.setState(name, stateRunning, nil)
+	return nil
+}
+
+// stopComponent stops c and records its state
+func (sv *service) stopComponent(c namedRunnable) <-chan bool {
+	sv.setState(c.name, stateStopping, nil)
+
+	stopped := c.Stop()
+	done := make(chan bool, 1)
+
+	// components stopped at once are done when this returns, see waitAll
+	select {
+	case <-stopped:
+		sv.setState(c.name, stateStopped, nil)
+		done <- true
+		return done
+	default:
+	}
+
+	go func() {
+		<-stopped
+		sv.setState(c.name, stateStopped, nil)
+		done <- true
+	}()
+
+	return done
+}
+
+// componentStatuses returns states of init components in registration order,
+// then of the other components
+func (sv *service) componentStatuses() []ComponentStatus {
+	sv.statesMu.Lock()
+	defer sv.statesMu.Unlock()
+
+	statuses := make([]ComponentStatus, 0, len(sv.initOrder)+len(sv.subServices))
+
+	status := func(name, kind string) ComponentStatus {
+		st, ok := sv.states[name]
+		if !ok {
+			st = ComponentStatus{Name: name, State: statePending}
+		}
+		st.Kind = kind
+		return st
+	}
+
+	for _, pre := range sv.initOrder {
+		statuses = append(statuses, status(pre, componentKindInit))
+	}
+
+	for _, subService := range sv.subServices {
+		statuses = append(statuses, status(subService.Name(), componentKindRunnable))
+	}
+
+	return statuses
+}
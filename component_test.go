@@ -0,0 +1,74 @@
+package goservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	sv := newTestService(WithInitRunnable(&testComponent{prefix: "gorm"}))
+
+	c, err := Get[*testComponent](sv, "gorm")
+	assert.NoError(t, err)
+	assert.Equal(t, "gorm", c.prefix)
+
+	_, err = Get[*testComponent](sv, "redis")
+	assert.True(t, errors.Is(err, ErrComponentNotFound))
+
+	_, err = Get[string](sv, "gorm")
+	assert.True(t, errors.Is(err, ErrComponentType))
+
+	assert.Panics(t, func() { MustGet[string](sv, "gorm") })
+}
+
+// disabledComponent has no value, like sdkgorm without database uri
+type disabledComponent struct {
+	testComponent
+}
+
+func (c *disabledComponent) Get() interface{} { return nil }
+
+// typedNilComponent has a nil client, like sdkredis without uri
+type typedNilComponent struct {
+	testComponent
+	client *testComponent
+}
+
+func (c *typedNilComponent) Get() interface{} { return c.client }
+
+func TestGetNotConfigured(t *testing.T) {
+	sv := newTestService(
+		WithInitRunnable(&disabledComponent{testComponent{prefix: "gorm"}}),
+		WithInitRunnable(&typedNilComponent{testComponent: testComponent{prefix: "redis"}}),
+	)
+
+	for _, prefix := range []string{"gorm", "redis"} {
+		v, err := Get[*testComponent](sv, prefix)
+		assert.Nil(t, v)
+		assert.True(t, errors.Is(err, ErrComponentNotConfigured), prefix)
+		assert.NotContains(t, err.Error(), "<nil>")
+	}
+}
+
+func TestInitRequirements(t *testing.T) {
+	sv := newTestService(
+		WithInitRunnable(&testComponent{prefix: "gorm"}),
+		WithRequirements(Require[*testComponent]("gorm")),
+	)
+	assert.NoError(t, sv.Init())
+
+	sv = newTestService(
+		WithInitRunnable(&testComponent{prefix: "gorm"}),
+		WithRequirements(Require[*testComponent]("redis")),
+	)
+	assert.True(t, errors.Is(sv.Init(), ErrComponentNotFound))
+
+	sv = newTestService(
+		WithInitRunnable(&testComponent{prefix: "gorm"}),
+		WithRequirements(Require[string]("gorm")),
+	)
+	assert.EqualError(t, sv.Init(),
+		`required components are not satisfied: component has unexpected type: "gorm" is *goservice.testComponent, not string`)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lequocbinh04/go-sdk/util/secret"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config files (yaml, json or toml) set values of flags which are not set by
+// command line arguments or env variables. Keys are flag names with dots instead
+// of dashes, they can be nested: "gorm-db-uri" is "gorm.db.uri" or
+//
+//	gorm:
+//	  db:
+//	    uri: ...
+//
+// Values of an env-specific file are applied on top of the base file,
+// with -app-env=prd the overlay of config.yaml is config.prd.yaml
+const (
+	configFileFlag   = "config-file"
+	appEnvFlag       = "app-env"
+	outEnvFormatFlag = "outenv-format"
+)
+
+// configKey returns the config file key of a flag
+func configKey(flagName string) string {
+	return strings.ToLower(strings.Replace(flagName, "-", ".", -1))
+}
+
+// envConfigPath returns path of the env-specific overlay of a config file
+func envConfigPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(path, ext), env, ext)
+}
+
+// readConfigFile reads a config file into flattened keys and values
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use yaml, json or toml", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %s", path, err.Error())
+	}
+
+	values := map[string]string{}
+	flattenConfig("", raw, values)
+	return values, nil
+}
+
+func flattenConfig(prefix string, raw map[string]interface{}, values map[string]string) {
+	for k, v := range raw {
+		key := configKey(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if m, ok := v.(map[string]interface{}); ok {
+			flattenConfig(key, m, values)
+			continue
+		}
+
+		values[key] = configValue(v)
+	}
+}
+
+func configValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i := range val {
+			items[i] = configValue(val[i])
+		}
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// GetSampleYAML prints all flags as a sample yaml config file
+func (f *AppFlagSet) GetSampleYAML() {
+	f.VisitAll(func(fl *flag.Flag) {
+		if fl.Name == "outenv" || fl.Name == outEnvFormatFlag || fl.Name == configFileFlag {
+			return
+		}
+
+		s := fmt.Sprintf("## %s (-%s)\n", fl.Usage, fl.Name)
+		s += fmt.Sprintf("#%s: ", configKey(fl.Name))
+
+		if fmt.Sprintf("%T", fl.Value) == "*flag.stringValue" {
+			// put quotes on the value
+			s += fmt.Sprintf("%q", secret.MaskFlag(fl.Name, fl.DefValue))
+		} else {
+			s += fl.DefValue
+		}
+		fmt.Print(s, "\n\n")
+	})
+}
@@ -0,0 +1,50 @@
+package goservice
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppFlagSetParseConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+
+	assert.NoError(t, os.WriteFile(base, []byte(`
+gorm:
+  db:
+    uri: base-uri
+    type: postgres
+go.redis.uri: redis://base
+ginPort: 3001
+`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.prd.yaml"), []byte(`
+gorm.db.uri: prd-uri
+`), 0644))
+
+	t.Setenv("GO_REDIS_URI", "redis://env")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	uri := fs.String("gorm-db-uri", "", "")
+	dbType := fs.String("gorm-db-type", "mysql", "")
+	redisUri := fs.String("go-redis-uri", "", "")
+	port := fs.Int("ginPort", 3000, "")
+	fs.String(configFileFlag, "", "")
+	fs.String(appEnvFlag, DevEnv, "")
+
+	set := newFlagSet("test", fs)
+	assert.NoError(t, set.Parse([]string{"-" + configFileFlag, base, "-" + appEnvFlag, PrdEnv, "-ginPort", "3002"}))
+
+	assert.Equal(t, "prd-uri", *uri)
+	assert.Equal(t, "postgres", *dbType)
+	assert.Equal(t, "redis://env", *redisUri)
+	assert.Equal(t, 3002, *port)
+
+	assert.Equal(t, sourceFile+":"+filepath.Join(dir, "config.prd.yaml"), set.sources["gorm-db-uri"])
+	assert.Equal(t, sourceFile+":"+base, set.sources["gorm-db-type"])
+	assert.Equal(t, sourceEnv, set.sources["go-redis-uri"])
+	assert.Equal(t, sourceFlag, set.sources["ginPort"])
+}
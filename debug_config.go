@@ -0,0 +1,39 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const debugConfigFlag = "debug-config-enabled"
+
+type configDump struct {
+	Name    string        `json:"name"`
+	Version string        `json:"version"`
+	Env     string        `json:"env"`
+	Config  []ConfigEntry `json:"config"`
+}
+
+func (sv *service) configDump() configDump {
+	return configDump{
+		Name:    sv.name,
+		Version: sv.version,
+		Env:     sv.env,
+		Config:  sv.cmdLine.Config(),
+	}
+}
+
+// configHandler mounts /debug/config which dumps the effective config with
+// masked values. It is mounted only if enabled by flag
+func (sv *service) configHandler(engine *gin.Engine) {
+	if !sv.debugConfigEnabled {
+		return
+	}
+
+	engine.GET("/debug/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, sv.configDump())
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dependencies between init components, used to order their startup and shutdown.
+//
+// A component declares its dependencies either by implementing HasDependencies
+// or by being registered with the WithDependencies option.
+
+// HasDependencies is implemented by init components which need other
+// init components to be running before they start
+type HasDependencies interface {
+	// Prefixes of init components this component depends on
+	DependsOn() []string
+}
+
+// WithDependencies declares that the init component with prefix depends on the
+// init components with prefixes dependsOn. It will be started after them and
+// stopped before them
+func WithDependencies(prefix string, dependsOn ...string) Option {
+	return func(s *service) {
+		s.dependencies[prefix] = append(s.dependencies[prefix], dependsOn...)
+	}
+}
+
+func (sv *service) dependenciesOf(prefix string) []string {
+	var deps []string
+
+	if hd, ok := unwrapComponent(sv.initServices[prefix]).(HasDependencies); ok {
+		deps = append(deps, hd.DependsOn()...)
+	}
+
+	return append(deps, sv.dependencies[prefix]...)
+}
+
+// sortedInitPrefixes returns prefixes of init components in topological order:
+// every component comes after all components it depends on.
+// Components without dependencies keep their registration order.
+func (sv *service) sortedInitPrefixes() ([]string, error) {
+	for prefix := range sv.dependencies {
+		if _, ok := sv.initServices[prefix]; !ok {
+			return nil, fmt.Errorf("dependencies declared for unknown component %q", prefix)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(sv.initOrder))
+	sorted := make([]string, 0, len(sv.initOrder))
+	var path []string
+
+	var visit func(prefix string) error
+	visit = func(prefix string) error {
+		switch state[prefix] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), prefix)
+		}
+
+		state[prefix] = visiting
+		path = append(path, prefix)
+
+		for _, dep := range sv.dependenciesOf(prefix) {
+			if _, ok := sv.initServices[dep]; !ok {
+				return fmt.Errorf("component %q depends on unknown component %q", prefix, dep)
+			}
+
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[prefix] = visited
+		sorted = append(sorted, prefix)
+		return nil
+	}
+
+	for _, prefix := range sv.initOrder {
+		if err := visit(prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
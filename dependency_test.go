@@ -0,0 +1,102 @@
+package goservice
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type testComponent struct {
+	prefix    string
+	dependsOn []string
+}
+
+func (c *testComponent) GetPrefix() string       { return c.prefix }
+func (c *testComponent) Get() interface{}        { return c }
+func (c *testComponent) Name() string            { return c.prefix }
+func (c *testComponent) InitFlags(*flag.FlagSet) {}
+func (c *testComponent) Configure() error        { return nil }
+func (c *testComponent) Run() error              { return nil }
+func (c *testComponent) DependsOn() []string     { return c.dependsOn }
+func (c *testComponent) Stop() <-chan bool {
+	ch := make(chan bool, 1)
+	ch <- true
+	return ch
+}
+
+func newTestService(opts ...Option) *service {
+	if logger.GetCurrent() == nil {
+		logger.InitServLogger(false)
+	}
+
+	sv := &service{
+		initServices: map[string]PrefixRunnable{},
+		dependencies: map[string][]string{},
+		overrides:    map[string]PrefixRunnable{},
+		stopped:      make(chan struct{}),
+		signalChan:   make(chan os.Signal, 1),
+		logger:       logger.GetCurrent().GetLogger("test"),
+
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(sv)
+	}
+
+	return sv
+}
+
+func TestSortedInitPrefixes(t *testing.T) {
+	sv := newTestService(
+		WithInitRunnable(&testComponent{prefix: "migration", dependsOn: []string{"gorm"}}),
+		WithInitRunnable(&testComponent{prefix: "sckio"}),
+		WithInitRunnable(&testComponent{prefix: "gorm"}),
+		WithInitRunnable(&testComponent{prefix: "redis"}),
+		WithDependencies("sckio", "redis"),
+	)
+
+	prefixes, err := sv.sortedInitPrefixes()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gorm", "migration", "redis", "sckio"}, prefixes)
+}
+
+func TestSortedInitPrefixesErrors(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		opts   []Option
+		expect string
+	}{
+		{
+			name: "cycle",
+			opts: []Option{
+				WithInitRunnable(&testComponent{prefix: "a", dependsOn: []string{"b"}}),
+				WithInitRunnable(&testComponent{prefix: "b", dependsOn: []string{"a"}}),
+			},
+			expect: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "missing dependency",
+			opts: []Option{
+				WithInitRunnable(&testComponent{prefix: "sckio"}),
+				WithDependencies("sckio", "redis"),
+			},
+			expect: `component "sckio" depends on unknown component "redis"`,
+		},
+		{
+			name: "unknown component",
+			opts: []Option{
+				WithDependencies("sckio", "redis"),
+			},
+			expect: `dependencies declared for unknown component "sckio"`,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := newTestService(c.opts...).sortedInitPrefixes()
+			assert.EqualError(t, err, c.expect)
+		})
+	}
+}
@@ -10,11 +10,9 @@ func main() {
 	service := goservice.New(
 		goservice.WithName("demo"),
 		goservice.WithVersion("1.0.0"),
+		goservice.WithInitRunnable(sdkes.NewES("test", "example")),
 	)
-	_ = service.Init()
-	newES := sdkes.NewES("test", "example")
-	newES.InitFlags()
-	err := newES.Run()
+	err := service.Init()
 	if err != nil {
 		logrus.Error("err: ", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/facebookgo/flagenv"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 )
 
 // isZeroValue guesses whether the string represents the zero
@@ -47,19 +48,29 @@ func getEnvName(name string) string {
 	return strings.ToUpper(name)
 }
 
+// Sources of flag values
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "file"
+	sourceDefault = "default"
+)
+
 type AppFlagSet struct {
 	*flag.FlagSet
+	// where the value of each flag comes from
+	sources map[string]string
 }
 
 func newFlagSet(name string, fs *flag.FlagSet) *AppFlagSet {
-	fSet := &AppFlagSet{fs}
+	fSet := &AppFlagSet{FlagSet: fs}
 	fSet.Usage = flagCustomUsage(name, fSet)
 	return fSet
 }
 
 func (f *AppFlagSet) GetSampleEnvs() {
 	f.VisitAll(func(f *flag.Flag) {
-		if f.Name == "outenv" {
+		if f.Name == "outenv" || f.Name == outEnvFormatFlag {
 			return
 		}
 
@@ -70,7 +81,7 @@ func (f *AppFlagSet) GetSampleEnvs() {
 			t := fmt.Sprintf("%T", f.Value)
 			if t == "*flag.stringValue" {
 				// put quotes on the value
-				s += fmt.Sprintf("%q", f.DefValue)
+				s += fmt.Sprintf("%q", secret.MaskFlag(f.Name, f.DefValue))
 			} else {
 				s += fmt.Sprintf("%v", f.DefValue)
 			}
@@ -79,9 +90,205 @@ func (f *AppFlagSet) GetSampleEnvs() {
 	})
 }
 
-func (f *AppFlagSet) Parse(args []string) {
-	flagenv.Parse()
-	_ = f.FlagSet.Parse(args)
+// Parse sets flags from command line arguments, env variables and config files.
+// The precedence is: flags > env > env-specific config file > base config file
+func (f *AppFlagSet) Parse(args []string) error {
+	if err := f.FlagSet.Parse(args); err != nil {
+		return err
+	}
+	return f.resolve()
+}
+
+// Reparse sets flags from env variables and config files again. Flags set by
+// command line arguments are kept, flags without any value go back to default.
+func (f *AppFlagSet) Reparse() error {
+	return f.resolve()
+}
+
+// resolve sets all flags which are not set by command line arguments
+func (f *AppFlagSet) resolve() error {
+	sources := map[string]string{}
+	values := map[string]string{}
+
+	f.Visit(func(fl *flag.Flag) { sources[fl.Name] = sourceFlag })
+	f.VisitAll(func(fl *flag.Flag) {
+		if sources[fl.Name] != "" {
+			return
+		}
+
+		if val := os.Getenv(getEnvName(fl.Name)); val != "" {
+			values[fl.Name] = val
+			sources[fl.Name] = sourceEnv
+		}
+	})
+
+	fileValues, files, err := f.readConfigFiles(values)
+	if err != nil {
+		return err
+	}
+
+	f.VisitAll(func(fl *flag.Flag) {
+		if err != nil || sources[fl.Name] == sourceFlag {
+			return
+		}
+
+		val, ok := values[fl.Name]
+		if !ok {
+			key := configKey(fl.Name)
+			if val, ok = fileValues[key]; ok {
+				sources[fl.Name] = sourceFile + ":" + files[key]
+			} else {
+				val = fl.DefValue
+				sources[fl.Name] = sourceDefault
+			}
+		}
+
+		if val == fl.Value.String() {
+			return
+		}
+
+		if ferr := fl.Value.Set(val); ferr != nil {
+			err = fmt.Errorf("failed to set flag %q with value %q", fl.Name, val)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	f.sources = sources
+	return nil
+}
+
+// readConfigFiles reads the config file and its overlay for the app env.
+// It returns values by config key and the file each value comes from
+func (f *AppFlagSet) readConfigFiles(envValues map[string]string) (map[string]string, map[string]string, error) {
+	// value of a flag which is not set by config files
+	valueOf := func(name string) (string, bool) {
+		fl := f.Lookup(name)
+		if fl == nil {
+			return "", false
+		}
+
+		explicit := false
+		f.Visit(func(v *flag.Flag) { explicit = explicit || v == fl })
+		if explicit {
+			return fl.Value.String(), true
+		}
+
+		if val, ok := envValues[name]; ok {
+			return val, true
+		}
+
+		return fl.DefValue, false
+	}
+
+	path, _ := valueOf(configFileFlag)
+	if path == "" {
+		return nil, nil, nil
+	}
+
+	values, err := readConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := map[string]string{}
+	for key := range values {
+		files[key] = path
+	}
+
+	env, isSet := valueOf(appEnvFlag)
+	if val := values[configKey(appEnvFlag)]; !isSet && val != "" {
+		env = val
+	}
+
+	overlayPath := envConfigPath(path, env)
+	if _, err := os.Stat(overlayPath); env == "" || err != nil {
+		return values, files, nil
+	}
+
+	overlay, err := readConfigFile(overlayPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key, val := range overlay {
+		values[key] = val
+		files[key] = overlayPath
+	}
+
+	return values, files, nil
+}
+
+// ConfigEntry describes the effective value of a flag
+type ConfigEntry struct {
+	Name    string `json:"name"`
+	EnvName string `json:"env_name"`
+	Usage   string `json:"usage"`
+	// Value and Default are masked if the flag is secret or contains credentials
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	Secret  bool   `json:"secret"`
+	// Source of the value: flag | env | file | default
+	Source string `json:"source"`
+	File   string `json:"file,omitempty"`
+}
+
+// Config returns the effective config: all flags with their masked values and sources
+func (f *AppFlagSet) Config() []ConfigEntry {
+	var entries []ConfigEntry
+
+	f.VisitAll(func(fl *flag.Flag) {
+		entry := ConfigEntry{
+			Name:    fl.Name,
+			EnvName: getEnvName(fl.Name),
+			Usage:   fl.Usage,
+			Value:   secret.MaskFlag(fl.Name, fl.Value.String()),
+			Default: secret.MaskFlag(fl.Name, fl.DefValue),
+			Secret:  secret.IsSecretFlag(fl.Name),
+			Source:  f.sources[fl.Name],
+		}
+
+		if strings.HasPrefix(entry.Source, sourceFile+":") {
+			entry.Source, entry.File = sourceFile, strings.TrimPrefix(entry.Source, sourceFile+":")
+		}
+
+		if entry.Source == "" {
+			entry.Source = sourceDefault
+		}
+
+		entries = append(entries, entry)
+	})
+
+	return entries
+}
+
+// clone returns a flag set with the same flags holding a copy of their values as text,
+// so it can be parsed again without changing options of running components.
+// Flags set by command line arguments stay set
+func (f *AppFlagSet) clone() *AppFlagSet {
+	fs := flag.NewFlagSet(f.Name(), flag.ContinueOnError)
+
+	f.VisitAll(func(fl *flag.Flag) {
+		fs.Var(&textValue{value: fl.Value.String()}, fl.Name, fl.Usage)
+		fs.Lookup(fl.Name).DefValue = fl.DefValue
+	})
+	f.Visit(func(fl *flag.Flag) { _ = fs.Set(fl.Name, fl.Value.String()) })
+
+	return &AppFlagSet{FlagSet: fs}
+}
+
+// textValue is a flag value kept as text, components parse it
+type textValue struct {
+	value string
+}
+
+func (v *textValue) String() string { return v.value }
+
+func (v *textValue) Set(value string) error {
+	v.value = value
+	return nil
 }
 
 // inspect from PrintDefaults
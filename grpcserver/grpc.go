@@ -0,0 +1,289 @@
+// Package grpcserver is a gRPC server component, it runs alongside the gin http server:
+//
+//	grpcServer := grpcserver.New("user-service")
+//	grpcServer.AddHandler(func(s *grpc.Server) { pb.RegisterUserServer(s, userServer) })
+//	service := goservice.New(goservice.WithRunnable(grpcServer))
+//
+// Requests are logged, traced with OpenTelemetry (see plugin/tracing), recovered from panics, and
+// *sdkcm.AppError returned by handlers are sent as gRPC status, see util/grpcerr.
+// The standard gRPC health service is registered.
+package grpcserver
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var (
+	defaultPort = 50051
+)
+
+// env of the service where error logs are not sent to clients
+const prdEnv = "prd"
+
+type Config struct {
+	Port     int    `json:"grpc_port"`
+	BindAddr string `json:"grpc_bind_addr"`
+}
+
+type grpcService struct {
+	Config
+	isEnabled bool
+	name      string
+	noLogger  bool
+	logger    logger.Logger
+	svr       *grpc.Server
+	health    *health.Server
+	mu        *sync.Mutex
+	handlers  []func(*grpc.Server)
+	options   []grpc.ServerOption
+	unary     []grpc.UnaryServerInterceptor
+	stream    []grpc.StreamServerInterceptor
+	// closed once Run listens or returns without listening
+	started     chan struct{}
+	startedOnce *sync.Once
+	// result of the graceful stop started by StopAccepting
+	shutdownDone chan struct{}
+	// flags of the service, to read its env
+	flags *flag.FlagSet
+}
+
+func New(name string) *grpcService {
+	return &grpcService{
+		name:        name,
+		mu:          &sync.Mutex{},
+		started:     make(chan struct{}),
+		startedOnce: &sync.Once{},
+	}
+}
+
+func (gs *grpcService) Name() string {
+	return gs.name + "-grpc"
+}
+
+func (gs *grpcService) InitFlags(fs *flag.FlagSet) {
+	prefix := "grpc"
+	fs.IntVar(&gs.Config.Port, prefix+"Port", defaultPort, "grpc server Port. If 0 => get a random Port")
+	fs.StringVar(&gs.BindAddr, prefix+"addr", "", "grpc server bind address")
+	fs.BoolVar(&gs.noLogger, "grpc-no-logger", false, "disable default grpc logger interceptor")
+	gs.flags = fs
+}
+
+// env of the service, set by the app-env flag
+func (gs *grpcService) env() string {
+	if gs.flags == nil {
+		return ""
+	}
+
+	if f := gs.flags.Lookup("app-env"); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func (gs *grpcService) Configure() error {
+	gs.logger = logger.GetCurrent().GetLogger("grpc")
+
+	// the first interceptor is the outermost one: errors are logged with their details
+	// before AppError hides them from clients, and panics of the ones added by
+	// AddUnaryInterceptor are recovered
+	withLog := gs.env() != prdEnv
+	// spans are exported by the global tracer provider, see plugin/tracing
+	unary := []grpc.UnaryServerInterceptor{otelgrpc.UnaryServerInterceptor(), UnaryAppError(withLog)}
+	stream := []grpc.StreamServerInterceptor{otelgrpc.StreamServerInterceptor(), StreamAppError(withLog)}
+
+	if !gs.noLogger {
+		unary = append(unary, UnaryLogger(gs.logger))
+		stream = append(stream, StreamLogger(gs.logger))
+	}
+
+	unary = append(unary, UnaryRecovery(gs.logger))
+	stream = append(stream, StreamRecovery(gs.logger))
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(append(unary, gs.unary...)...),
+		grpc.ChainStreamInterceptor(append(stream, gs.stream...)...),
+	}
+
+	gs.mu.Lock()
+	gs.svr = grpc.NewServer(append(opts, gs.options...)...)
+	gs.health = health.NewServer()
+	gs.shutdownDone = nil
+	gs.mu.Unlock()
+
+	return nil
+}
+
+func formatBindAddr(s string, p int) string {
+	if strings.Contains(s, ":") && !strings.Contains(s, "[") {
+		s = "[" + s + "]"
+	}
+	return fmt.Sprintf("%s:%d", s, p)
+}
+
+func (gs *grpcService) Run() error {
+	defer gs.setStarted()
+
+	if !gs.isEnabled {
+		return nil
+	}
+
+	if err := gs.Configure(); err != nil {
+		return err
+	}
+
+	healthpb.RegisterHealthServer(gs.svr, gs.health)
+
+	for _, hdl := range gs.handlers {
+		hdl(gs.svr)
+	}
+
+	addr := formatBindAddr(gs.BindAddr, gs.Config.Port)
+	gs.logger.Debugf("start listen tcp %s...", addr)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	gs.mu.Lock()
+	gs.Config.Port = lis.Addr().(*net.TCPAddr).Port
+	gs.mu.Unlock()
+
+	// the whole server and each registered service are serving
+	for service := range gs.svr.GetServiceInfo() {
+		gs.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	gs.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	gs.setStarted()
+	gs.logger.Infof("listen on %s...", lis.Addr().String())
+
+	return gs.svr.Serve(lis)
+}
+
+func (gs *grpcService) setStarted() {
+	gs.startedOnce.Do(func() { close(gs.started) })
+}
+
+// Port blocks until the server listens and returns its port,
+// the actual one if it is configured with port 0
+func (gs *grpcService) Port() int {
+	<-gs.started
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.Config.Port
+}
+
+func (gs *grpcService) URI() string {
+	return formatBindAddr(gs.BindAddr, gs.Config.Port)
+}
+
+func (gs *grpcService) Stop() <-chan bool {
+	c := make(chan bool)
+
+	go func() {
+		gs.mu.Lock()
+		svr, draining := gs.svr, gs.shutdownDone != nil
+		gs.mu.Unlock()
+
+		if svr != nil {
+			if draining {
+				// cancel calls left by Drain
+				svr.Stop()
+			} else {
+				gs.health.Shutdown()
+				svr.GracefulStop()
+			}
+		}
+		c <- true
+	}()
+	return c
+}
+
+// StopAccepting reports NOT_SERVING to health checks and stops accepting
+// connections, in-flight calls keep running until Drain
+func (gs *grpcService) StopAccepting(ctx context.Context) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.svr == nil || gs.shutdownDone != nil {
+		return nil
+	}
+
+	gs.health.Shutdown()
+
+	svr, done := gs.svr, make(chan struct{})
+	go func() {
+		svr.GracefulStop()
+		close(done)
+	}()
+	gs.shutdownDone = done
+
+	return nil
+}
+
+// Drain waits until in-flight calls are done
+func (gs *grpcService) Drain(ctx context.Context) error {
+	gs.mu.Lock()
+	done := gs.shutdownDone
+	gs.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AddHandler registers gRPC services, it enables the server
+func (gs *grpcService) AddHandler(hdl func(*grpc.Server)) {
+	gs.isEnabled = true
+	gs.handlers = append(gs.handlers, hdl)
+}
+
+// AddServerOption adds options of the grpc.Server. Ex: grpc.MaxRecvMsgSize
+func (gs *grpcService) AddServerOption(opts ...grpc.ServerOption) {
+	gs.options = append(gs.options, opts...)
+}
+
+// AddUnaryInterceptor adds interceptors run after the default ones, in order
+func (gs *grpcService) AddUnaryInterceptor(interceptors ...grpc.UnaryServerInterceptor) {
+	gs.unary = append(gs.unary, interceptors...)
+}
+
+// AddStreamInterceptor adds interceptors run after the default ones, in order
+func (gs *grpcService) AddStreamInterceptor(interceptors ...grpc.StreamServerInterceptor) {
+	gs.stream = append(gs.stream, interceptors...)
+}
+
+// HealthServer returns the standard health service to set status of gRPC services.
+// It is nil until the server runs
+func (gs *grpcService) HealthServer() *health.Server {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.health
+}
+
+func (gs *grpcService) GetConfig() Config {
+	return gs.Config
+}
+
+func (gs *grpcService) IsRunning() bool {
+	return gs.svr != nil
+}
@@ -0,0 +1,143 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/grpcerr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer(t *testing.T) (*grpcService, healthpb.HealthClient) {
+	logger.InitServLogger(false)
+
+	gs := New("test")
+	gs.Config = Config{BindAddr: "127.0.0.1"}
+	gs.AddHandler(func(s *grpc.Server) {})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- gs.Run() }()
+
+	port := gs.Port()
+	require.NotZero(t, port)
+
+	conn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		<-gs.Stop()
+		assert.NoError(t, <-errChan)
+	})
+
+	return gs, healthpb.NewHealthClient(conn)
+}
+
+func TestHealth(t *testing.T) {
+	gs, client := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	resp, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "grpc.health.v1.Health"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// StopAccepting reports not serving before closing the listener
+	gs.health.Shutdown()
+	resp, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
+
+func TestGracefulStop(t *testing.T) {
+	gs, _ := newTestServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, gs.StopAccepting(ctx))
+	require.NoError(t, gs.Drain(ctx))
+}
+
+func interceptorChain(l logger.Logger, withLog bool) func(handler grpc.UnaryHandler) error {
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+
+	return func(handler grpc.UnaryHandler) error {
+		_, err := UnaryAppError(withLog)(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return UnaryLogger(l)(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return UnaryRecovery(l)(ctx, req, info, handler)
+			})
+		})
+		return err
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	logger.InitServLogger(false)
+	chain := interceptorChain(logger.GetCurrent().GetLogger("grpc"), true)
+
+	err := chain(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, sdkcm.ErrNoPermission(errors.New("not owner"))
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	appErr, ok := grpcerr.FromError(err).(*sdkcm.AppError)
+	require.True(t, ok)
+	assert.Equal(t, "ErrNoPermission", appErr.Key)
+	assert.Equal(t, "not owner", appErr.Log)
+
+	err = chain(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic(sdkcm.ErrInvalidRequest(errors.New("invalid id")))
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	err = chain(func(ctx context.Context, req interface{}) (interface{}, error) {
+		var m map[string]int
+		m["a"] = 1
+		return nil, nil
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, panicMessage, status.Convert(err).Message())
+}
+
+func TestInterceptorsHideLog(t *testing.T) {
+	logger.InitServLogger(false)
+	chain := interceptorChain(logger.GetCurrent().GetLogger("grpc"), false)
+
+	err := chain(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, sdkcm.ErrDB(errors.New("dial tcp 10.0.0.1:5432"))
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	appErr := grpcerr.FromError(err).(*sdkcm.AppError)
+	assert.Equal(t, "DB_ERROR", appErr.Key)
+	assert.Empty(t, appErr.Log)
+	assert.NotContains(t, err.Error(), "10.0.0.1")
+
+	err = chain(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New("pq: relation users does not exist")
+	})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.NotContains(t, err.Error(), "users")
+}
+
+func TestDisabled(t *testing.T) {
+	gs := New("test")
+	assert.NoError(t, gs.Run())
+	assert.False(t, gs.IsRunning())
+	<-gs.Stop()
+}
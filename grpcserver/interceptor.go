@@ -0,0 +1,108 @@
+package grpcserver
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// message of panics sent to clients, details are in the server log only
+const panicMessage = "internal server error"
+
+// UnaryLogger logs every call with its code and duration
+func UnaryLogger(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(l, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLogger logs every stream with its code and duration
+func StreamLogger(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(l, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logCall logs err with its details, the interceptor must run before UnaryAppError
+// which hides them from clients
+func logCall(l logger.Logger, method string, start time.Time, err error) {
+	code := grpcerr.ToStatusWithLog(err).Code()
+	if code == codes.Unknown || code == codes.Internal || code == codes.DataLoss {
+		l.Errorf("%s | %s | %v | %s", method, code, time.Since(start), err)
+		return
+	}
+	l.Infof("%s | %s | %v", method, code, time.Since(start))
+}
+
+// UnaryAppError sends *sdkcm.AppError returned by handlers as gRPC status.
+// Log of AppError and text of other errors are sent only if withLog, it must be false in prd env
+func UnaryAppError(withLog bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, toError(err, withLog)
+	}
+}
+
+// StreamAppError sends *sdkcm.AppError returned by handlers as gRPC status, see UnaryAppError
+func StreamAppError(withLog bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toError(handler(srv, ss), withLog)
+	}
+}
+
+func toError(err error, withLog bool) error {
+	if withLog {
+		return grpcerr.ToErrorWithLog(err)
+	}
+	return grpcerr.ToError(err)
+}
+
+// UnaryRecovery returns panics of handlers as errors, so they don't crash the server.
+// An *sdkcm.AppError panic is returned as is, others are logged with their stack
+// and returned as Internal with a generic message
+func UnaryRecovery(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverError(l, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery returns panics of handlers as errors, see UnaryRecovery
+func StreamRecovery(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverError(l, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recoverError(l logger.Logger, method string, r interface{}) error {
+	if appErr, ok := r.(*sdkcm.AppError); ok {
+		return appErr
+	}
+
+	l.Errorf("%s | panic: %v\n%s", method, r, debug.Stack())
+	return status.Error(codes.Internal, panicMessage)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthStatusUp   = "up"
+	healthStatusDown = "down"
+
+	defaultHealthCheckTimeout = 3 * time.Second
+
+	readinessPath = "/readyz"
+)
+
+type componentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components,omitempty"`
+}
+
+type namedHealthChecker struct {
+	name    string
+	checker HealthChecker
+}
+
+// healthCheckers returns all components implementing HealthChecker.
+// Init components are named by their prefix, the others by their name
+func (sv *service) healthCheckers() []namedHealthChecker {
+	var checkers []namedHealthChecker
+
+	for _, pre := range sv.initOrder {
+		if hc, ok := unwrapComponent(sv.initServices[pre]).(HealthChecker); ok {
+			checkers = append(checkers, namedHealthChecker{name: pre, checker: hc})
+		}
+	}
+
+	for _, subService := range sv.subServices {
+		if hc, ok := unwrapComponent(subService).(HealthChecker); ok {
+			checkers = append(checkers, namedHealthChecker{name: subService.Name(), checker: hc})
+		}
+	}
+
+	return checkers
+}
+
+// checkHealth runs health checks of all components at the same time
+func (sv *service) checkHealth(ctx context.Context) healthReport {
+	ctx, cancel := context.WithTimeout(ctx, defaultHealthCheckTimeout)
+	defer cancel()
+
+	checkers := sv.healthCheckers()
+	report := healthReport{
+		Status:     healthStatusUp,
+		Components: make(map[string]componentHealth, len(checkers)),
+	}
+
+	locker := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(checkers))
+
+	for _, c := range checkers {
+		go func(c namedHealthChecker) {
+			defer wg.Done()
+
+			ch := componentHealth{Status: healthStatusUp}
+			if err := c.checker.Health(ctx); err != nil {
+				ch = componentHealth{Status: healthStatusDown, Error: err.Error()}
+			}
+
+			locker.Lock()
+			report.Components[c.name] = ch
+			if ch.Status == healthStatusDown {
+				report.Status = healthStatusDown
+			}
+			locker.Unlock()
+		}(c)
+	}
+
+	wg.Wait()
+	return report
+}
+
+func (sv *service) isReady() bool {
+	return atomic.LoadInt32(&sv.ready) == 1
+}
+
+func (sv *service) setReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&sv.ready, v)
+}
+
+// healthHandler mounts health endpoints for Kubernetes probes:
+//   - /livez: the process is alive
+//   - /healthz: all components are healthy
+//   - /readyz: the service is started and all components are healthy
+//
+// They are mounted on the HTTP server, which only runs if the app adds a handler to it,
+// and on the admin server. Services without HTTP handlers (Ex: workers) must set admin-addr
+// to serve them, see warnNoHealthEndpoints
+func (sv *service) healthHandler(engine *gin.Engine) {
+	engine.GET("/livez", func(c *gin.Context) {
+		c.JSON(http.StatusOK, healthReport{Status: healthStatusUp})
+	})
+
+	engine.GET("/healthz", func(c *gin.Context) {
+		report := sv.checkHealth(c.Request.Context())
+		c.JSON(healthStatusCode(report), report)
+	})
+
+	engine.GET(readinessPath, func(c *gin.Context) {
+		report := sv.checkHealth(c.Request.Context())
+		if !sv.isReady() {
+			report.Status = healthStatusDown
+		}
+		c.JSON(healthStatusCode(report), report)
+	})
+}
+
+func healthStatusCode(report healthReport) int {
+	if report.Status != healthStatusUp {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// warnNoHealthEndpoints logs an error if neither the HTTP server nor the admin server runs,
+// probes of the service would fail without a hint
+func (sv *service) warnNoHealthEndpoints() {
+	if r, ok := sv.httpServer.(interface{ IsRunning() bool }); ok && r.IsRunning() {
+		return
+	}
+
+	if sv.admin != nil && sv.admin.addr != "" {
+		return
+	}
+
+	sv.logger.Errorf("health endpoints /livez, /healthz and %s are not served: the http server has no handler "+
+		"and -%s is empty, set it to serve them", readinessPath, adminAddrFlag)
+}
@@ -0,0 +1,58 @@
+package goservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// healthComponent is a sub service with a health check
+type healthComponent struct {
+	testComponent
+	err error
+}
+
+func (c *healthComponent) Health(ctx context.Context) error { return c.err }
+
+func TestHealthEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := &healthComponent{testComponent: testComponent{prefix: "db"}}
+	sv := newTestService()
+	sv.subServices = append(sv.subServices, db)
+
+	engine := gin.New()
+	sv.healthHandler(engine)
+
+	status := func(path string) int {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	// before Start is done
+	assert.Equal(t, http.StatusOK, status("/livez"))
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+
+	sv.setReady(true)
+	assert.Equal(t, http.StatusOK, status("/livez"))
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	assert.Equal(t, http.StatusOK, status("/readyz"))
+
+	db.err = errors.New("connection refused")
+	assert.Equal(t, http.StatusOK, status("/livez"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+
+	// stopping
+	db.err = nil
+	sv.setReady(false)
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+}
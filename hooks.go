@@ -0,0 +1,80 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"fmt"
+
+	"github.com/lequocbinh04/go-sdk/httpserver"
+)
+
+// Hook is a function run at a step of the service lifecycle
+type Hook func(sc ServiceContext) error
+
+// WithOnStart adds hooks run by Start before sub services (Ex: the HTTP server) run.
+// Hooks run in the order they are added, if one fails the service is stopped
+// and Start returns its error
+func WithOnStart(hooks ...Hook) Option {
+	return func(s *service) { s.onStart = append(s.onStart, hooks...) }
+}
+
+// WithOnReady adds hooks run by Start when sub services run and the HTTP server listens,
+// before the service is ready (see /readyz). Ex: warming caches, registering consumers.
+// Hooks run in the order they are added, if one fails the service is stopped
+// and Start returns its error
+func WithOnReady(hooks ...Hook) Option {
+	return func(s *service) { s.onReady = append(s.onReady, hooks...) }
+}
+
+// WithOnStop adds hooks run by Stop before components are stopped.
+// Hooks run in the reverse order they are added, all of them run even if one fails
+func WithOnStop(hooks ...Hook) Option {
+	return func(s *service) { s.onStop = append(s.onStop, hooks...) }
+}
+
+// runHooks runs hooks in order until one fails
+func (sv *service) runHooks(step string, hooks []Hook) error {
+	for _, hook := range hooks {
+		if err := hook(sv); err != nil {
+			return fmt.Errorf("%s hook: %w", step, err)
+		}
+	}
+	return nil
+}
+
+// runStopHooks runs all stop hooks in reverse order and returns the first error
+func (sv *service) runStopHooks() error {
+	var firstErr error
+
+	for i := len(sv.onStop) - 1; i >= 0; i-- {
+		if err := sv.onStop[i](sv); err != nil {
+			sv.logger.Errorln("OnStop hook:", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("OnStop hook: %w", err)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// waitStarted blocks until the HTTP server listens. It returns the error of the HTTP server
+// if it failed to listen, or of a sub service which already failed
+func (sv *service) waitStarted(c <-chan error) error {
+	if gs, ok := sv.httpServer.(httpserver.GinService); ok {
+		if err := gs.WaitStarted(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case err := <-c:
+			if err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
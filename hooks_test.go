@@ -0,0 +1,83 @@
+package goservice
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	hook := func(name string, err error) Hook {
+		return func(sc ServiceContext) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	ready := make(chan struct{})
+	sv := newTestService(
+		WithOnStart(hook("start:1", nil), hook("start:2", nil)),
+		WithOnReady(hook("ready", nil), func(sc ServiceContext) error {
+			close(ready)
+			return nil
+		}),
+		WithOnStop(hook("stop:1", nil), hook("stop:2", errors.New("stop failed"))),
+	)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- sv.Start(nil) }()
+
+	<-ready
+	sv.Stop()
+
+	assert.EqualError(t, <-errChan, "OnStop hook: stop failed")
+	assert.Equal(t, []string{"start:1", "start:2", "ready", "stop:2", "stop:1"}, calls)
+}
+
+func TestOnReadyFailure(t *testing.T) {
+	var calls []string
+	recorder := &shutdownRecorder{}
+
+	sv := newTestService(
+		WithInitRunnable(&gracefulComponent{testComponent: testComponent{prefix: "gorm"}, recorder: recorder}),
+		WithOnReady(func(sc ServiceContext) error { return errors.New("cache failed") }),
+		WithOnStop(func(sc ServiceContext) error {
+			calls = append(calls, "stop")
+			return nil
+		}),
+	)
+
+	err := sv.Start(nil)
+	assert.EqualError(t, err, "OnReady hook: cache failed")
+	assert.Equal(t, []string{"stop"}, calls)
+	assert.Equal(t, []string{"accept:gorm", "drain:gorm", "stop:gorm"}, recorder.calls)
+	assert.False(t, sv.isReady())
+}
+
+func TestHTTPServerListenFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	readyCalled := false
+	sv := newTestService(WithOnReady(func(sc ServiceContext) error {
+		readyCalled = true
+		return nil
+	}))
+
+	httpServer := httpserver.New("test", "")
+	httpServer.Listen = busy.Addr().String()
+	httpServer.AddHandler(func(engine *gin.Engine) {})
+	sv.httpServer = httpServer
+	sv.subServices = append(sv.subServices, httpServer)
+
+	assert.ErrorContains(t, sv.Start(nil), "address already in use")
+	assert.False(t, readyCalled)
+	assert.False(t, sv.isReady())
+}
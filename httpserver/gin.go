@@ -2,13 +2,15 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
 	"github.com/lequocbinh04/go-sdk/httpserver/middleware"
 	"github.com/lequocbinh04/go-sdk/logger"
-	"go.opencensus.io/plugin/ochttp"
+	"github.com/lequocbinh04/go-sdk/util/flagset"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net"
 	"net/http"
 	"strings"
@@ -17,8 +19,6 @@ import (
 )
 
 var (
-	ginMode     string
-	ginNoLogger bool
 	defaultPort = 3000
 )
 
@@ -26,11 +26,17 @@ type Config struct {
 	Port         int    `json:"http_port"`
 	BindAddr     string `json:"http_bind_addr"`
 	GinNoDefault bool   `json:"http_no_default"`
+	// addresses replacing BindAddr:Port, see ParseListenAddrs
+	Listen string `json:"http_listen"`
 }
 
 type GinService interface {
 	// block until ready
 	Port() int
+	// block until ready, it returns the error of Run if it failed to listen
+	WaitStarted() error
+	// http or https
+	Scheme() string
 	isGinService()
 }
 
@@ -38,22 +44,56 @@ type ginService struct {
 	Config
 	isEnabled bool
 	name      string
+	mode      string
+	noLogger  bool
 	SentryDsn string
 	logger    logger.Logger
 	svr       *myHttpServer
 	router    *gin.Engine
 	mu        *sync.Mutex
 	handlers  []func(*gin.Engine)
+	// handlers mounted by the SDK itself, they don't enable the server
+	systemHandlers []func(*gin.Engine)
+	// config the server is listening with: as requested and with resolved port
+	listenCfg Config
+	activeCfg Config
+	// addresses the server is bound to, it listens on them again if a restart fails
+	activeAddrs []ListenAddr
+	// closed once Run listens or returns without listening, with the error of Run
+	started     chan struct{}
+	startedOnce *sync.Once
+	startErr    error
+	// result of the graceful shutdown started by StopAccepting
+	shutdownDone chan error
+	// HTTPS is served if certificate files are set
+	tlsCfg TLSConfig
+	// flags of the service, to read its env
+	flags *flag.FlagSet
+	// default format of rendered errors, see middleware.ErrorFormat
+	errorFormat string
+	// running is true while Run serves, restart is set by Reload until Run listens with it
+	running bool
+	restart *restart
+	// set by Stop, so Run or a restart in progress doesn't serve again
+	stopped bool
 	//registeredID  string
 	//registryAgent registry.Agent
 }
 
+// restart asks Run to listen with cfg, the result of listening is sent on done
+type restart struct {
+	cfg  Config
+	done chan error
+}
+
 func New(name, sentryDsn string) *ginService {
 	return &ginService{
-		name:      name,
-		SentryDsn: sentryDsn,
-		mu:        &sync.Mutex{},
-		handlers:  []func(*gin.Engine){},
+		name:        name,
+		SentryDsn:   sentryDsn,
+		mu:          &sync.Mutex{},
+		handlers:    []func(*gin.Engine){},
+		started:     make(chan struct{}),
+		startedOnce: &sync.Once{},
 	}
 }
 
@@ -61,23 +101,57 @@ func (gs *ginService) Name() string {
 	return gs.name + "-gin"
 }
 
-func (gs *ginService) InitFlags() {
+func (gs *ginService) InitFlags(fs *flag.FlagSet) {
+	gs.Config.initFlags(fs)
+	fs.StringVar(&gs.mode, "gin-mode", "", "gin mode")
+	fs.BoolVar(&gs.noLogger, "gin-no-logger", false, "disable default gin logger middleware")
+	fs.StringVar(&gs.errorFormat, "gin-error-format", middleware.ErrorFormatJSON,
+		"Format of errors if clients don't ask for one with the Accept header: json | problem (RFC 7807 problem+json)")
+	gs.tlsCfg.initFlags(fs)
+	gs.flags = fs
+}
+
+// initFlags binds the addresses of the server, Reconfigure binds them to a copy of Config
+func (cfg *Config) initFlags(fs *flag.FlagSet) {
 	prefix := "gin"
-	flag.IntVar(&gs.Config.Port, prefix+"Port", defaultPort, "gin server Port. If 0 => get a random Port")
-	flag.StringVar(&gs.BindAddr, prefix+"addr", "", "gin server bind address")
-	flag.StringVar(&ginMode, "gin-mode", "", "gin mode")
-	flag.BoolVar(&ginNoLogger, "gin-no-logger", false, "disable default gin logger middleware")
+	fs.IntVar(&cfg.Port, prefix+"Port", defaultPort, "gin server Port. If 0 => get a random Port")
+	fs.StringVar(&cfg.BindAddr, prefix+"addr", "", "gin server bind address")
+	fs.StringVar(&cfg.Listen, "gin-listen", "", "Comma-separated addresses to listen on instead of ginaddr:ginPort. "+
+		"Ex: :3000,tcp://127.0.0.1:9090,unix:///run/app.sock,fd://3,systemd (sockets passed by systemd)")
+}
+
+// env of the service, set by the app-env flag
+func (gs *ginService) env() string {
+	if gs.flags == nil {
+		return ""
+	}
+
+	if f := gs.flags.Lookup("app-env"); f != nil {
+		return f.Value.String()
+	}
+	return ""
 }
 
 func (gs *ginService) Configure() error {
 	gs.logger = logger.GetCurrent().GetLogger("gin")
 
-	if ginMode == "release" {
+	if gs.errorFormat != "" {
+		if err := middleware.ValidateErrorFormat(gs.errorFormat); err != nil {
+			return err
+		}
+	}
+
+	if gs.mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	gs.logger.Debug("init gin engine...")
 	gs.router = gin.New()
+	gs.router.Use(middleware.SpanRoute())
+
+	if !gs.GinNoDefault {
+		gs.router.Use(middleware.RequestID(), middleware.ErrorFormat(gs.errorFormat))
+	}
 
 	if gs.SentryDsn != "" {
 		tracesSampleRate := 0.3
@@ -101,20 +175,27 @@ func (gs *ginService) Configure() error {
 	}
 
 	if !gs.GinNoDefault {
-		if !ginNoLogger {
-			gs.router.Use(gin.Logger())
+		if !gs.noLogger {
+			// runs after RequestID, so access logs have the ID of the request
+			gs.router.Use(Logger(gs.logger))
 		}
 		//gs.router.Use(gin.Recovery())
-		gs.router.Use(middleware.PanicLogger())
+		gs.router.Use(middleware.Recover(gs.env()))
 	}
 
-	och := &ochttp.Handler{
-		Handler: gs.router,
-	}
+	// spans are exported by the global tracer provider, see plugin/tracing
+	handler := otelhttp.NewHandler(gs.router, gs.name,
+		otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			return r.Method
+		}),
+	)
 
+	gs.mu.Lock()
 	gs.svr = &myHttpServer{
-		Server: http.Server{Handler: och},
+		Server: http.Server{Handler: handler},
 	}
+	gs.shutdownDone = nil
+	gs.mu.Unlock()
 
 	return nil
 }
@@ -126,63 +207,357 @@ func formatBindAddr(s string, p int) string {
 	return fmt.Sprintf("%s:%d", s, p)
 }
 
-func (gs *ginService) Run() error {
+func (gs *ginService) Run() (err error) {
+	defer func() { gs.setStarted(err) }()
+
 	if !gs.isEnabled {
 		return nil
 	}
 
+	gs.mu.Lock()
+	stopped := gs.stopped
+	gs.mu.Unlock()
+
+	// Stop was called before Run, ex: another component failed to start
+	if stopped {
+		return nil
+	}
+
 	if err := gs.Configure(); err != nil {
 		return err
 	}
 
+	var reloader *certReloader
+	if gs.tlsCfg.enabled() {
+		var err error
+		if reloader, err = newCertReloader(gs.tlsCfg, gs.logger); err != nil {
+			return err
+		}
+
+		stop := make(chan struct{})
+		defer close(stop)
+		go reloader.watch(stop)
+	}
+
+	gs.mu.Lock()
+	cfg := gs.Config
+	gs.mu.Unlock()
+
+	listeners, activeCfg, err := gs.listen(cfg)
+	if err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	gs.listenCfg, gs.activeCfg, gs.Config = cfg, activeCfg, activeCfg
+	gs.running = true
+	gs.mu.Unlock()
+	gs.setStarted(nil)
+
+	for {
+		err := gs.serve(listeners, reloader)
+
+		gs.mu.Lock()
+		req := gs.restart
+		gs.restart = nil
+		gs.running = req != nil
+		gs.mu.Unlock()
+
+		if req == nil {
+			return err
+		}
+
+		if listeners, err = gs.relisten(req); err != nil {
+			gs.mu.Lock()
+			gs.running = false
+			gs.mu.Unlock()
+			return err
+		}
+
+		if err := gs.Configure(); err != nil {
+			closeAll(listeners)
+			return err
+		}
+	}
+}
+
+// listen listens on addresses of cfg and keeps the addresses it is bound to,
+// it returns cfg with the port actually listened on
+func (gs *ginService) listen(cfg Config) ([]net.Listener, Config, error) {
+	addrs, err := listenAddrs(cfg)
+	if err != nil {
+		return nil, cfg, err
+	}
+
+	gs.logger.Debugf("start listen %v...", addrs)
+	listeners, err := listenAll(addrs)
+	if err != nil {
+		return nil, cfg, err
+	}
+	gs.activeAddrs = boundAddrs(addrs, listeners)
+
+	if tcp, ok := primaryTCPAddr(listeners); ok {
+		if cfg.Listen != "" {
+			cfg.BindAddr = ""
+			if !tcp.IP.IsUnspecified() {
+				cfg.BindAddr = tcp.IP.String()
+			}
+		}
+		cfg.Port = tcp.Port
+	}
+
+	return listeners, cfg, nil
+}
+
+// relisten listens with the config of a restart and sends the result to Reload.
+// If it fails, the server listens again on its old addresses
+func (gs *ginService) relisten(req *restart) ([]net.Listener, error) {
+	oldAddrs := gs.activeAddrs
+	listeners, activeCfg, err := gs.listen(req.cfg)
+	req.done <- err
+	if err == nil {
+		gs.mu.Lock()
+		gs.listenCfg, gs.activeCfg, gs.Config = req.cfg, activeCfg, activeCfg
+		gs.mu.Unlock()
+		return listeners, nil
+	}
+
+	gs.logger.Errorln("failed to restart gin server, keep old config:", err)
+	if listeners, err = listenAll(oldAddrs); err != nil {
+		return nil, err
+	}
+
+	gs.mu.Lock()
+	gs.Config = gs.activeCfg
+	gs.mu.Unlock()
+	return listeners, nil
+}
+
+// serve mounts handlers on the router created by Configure and serves it on listeners
+// until the server is shut down
+func (gs *ginService) serve(listeners []net.Listener, reloader *certReloader) error {
+	for _, hdl := range gs.systemHandlers {
+		hdl(gs.router)
+	}
+
 	for _, hdl := range gs.handlers {
 		hdl(gs.router)
 	}
 
-	addr := formatBindAddr(gs.BindAddr, gs.Config.Port)
-	gs.logger.Debugf("start listen tcp %s...", addr)
-	lis, err := net.Listen("tcp", addr)
+	gs.mu.Lock()
+	svr, stopped, port := gs.svr, gs.stopped, gs.Config.Port
+	gs.mu.Unlock()
+
+	// Stop was called while listening or restarting
+	if stopped {
+		closeAll(listeners)
+		return nil
+	}
+
+	serve := svr.Serve
+	if reloader != nil {
+		svr.TLSConfig = reloader.tlsConfig()
+
+		if gs.tlsCfg.RedirectPort > 0 {
+			redirect, err := gs.serveRedirect(port)
+			if err != nil {
+				closeAll(listeners)
+				return err
+			}
+			defer redirect.Close()
+		}
+
+		serve = func(lis net.Listener) error { return svr.ServeTLS(lis, "", "") }
+	}
+
+	for _, lis := range listeners {
+		gs.logger.Infof("listen on %s://%s...", lis.Addr().Network(), lis.Addr().String())
+	}
+
+	return serveAll(svr, listeners, serve)
+}
+
+// boundAddrs returns addresses listeners are bound to, with resolved ports.
+// Inherited sockets keep their fd address, they stay bound while the server restarts
+func boundAddrs(addrs []ListenAddr, listeners []net.Listener) []ListenAddr {
+	bound := make([]ListenAddr, 0, len(listeners))
+	for i, lis := range listeners {
+		if addrs[i].Network == "fd" {
+			bound = append(bound, addrs[i])
+			continue
+		}
+		bound = append(bound, ListenAddr{Network: lis.Addr().Network(), Address: lis.Addr().String()})
+	}
+	return bound
+}
+
+func closeAll(listeners []net.Listener) {
+	for _, lis := range listeners {
+		_ = lis.Close()
+	}
+}
+
+// listenAddrs returns addresses of gin-listen, or ginaddr:ginPort if it is empty
+func listenAddrs(cfg Config) ([]ListenAddr, error) {
+	if cfg.Listen == "" {
+		return []ListenAddr{{Network: "tcp", Address: formatBindAddr(cfg.BindAddr, cfg.Port)}}, nil
+	}
+
+	addrs, err := ParseListenAddrs(cfg.Listen)
 	if err != nil {
-		gs.logger.Fatalf("failed to listen: %v", err)
+		return nil, err
 	}
 
-	gs.Config.Port = getPort(lis)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address in gin-listen %q", cfg.Listen)
+	}
 
-	gs.logger.Infof("listen on %s...", lis.Addr().String())
+	return addrs, nil
+}
 
-	err = gs.svr.Serve(lis)
+// primaryTCPAddr returns the address of the first TCP listener
+func primaryTCPAddr(listeners []net.Listener) (*net.TCPAddr, bool) {
+	for _, lis := range listeners {
+		if tcp, ok := lis.Addr().(*net.TCPAddr); ok {
+			return tcp, true
+		}
+	}
+	return nil, false
+}
 
-	if err != nil && err == http.ErrServerClosed {
-		return nil
+// serveAll serves the router on all listeners until the server is shut down.
+// If one of them fails, the others are closed
+func serveAll(svr *myHttpServer, listeners []net.Listener, serve func(net.Listener) error) error {
+	errChan := make(chan error, len(listeners))
+	for _, lis := range listeners {
+		go func(lis net.Listener) { errChan <- serve(lis) }(lis)
+	}
+
+	var firstErr error
+	for range listeners {
+		err := <-errChan
+		if err == nil || err == http.ErrServerClosed {
+			continue
+		}
+
+		if firstErr == nil {
+			firstErr = err
+			_ = svr.Close()
+		}
+	}
+
+	return firstErr
+}
+
+// serveRedirect listens on the redirect port and redirects requests to HTTPS,
+// it is closed when the server stops
+func (gs *ginService) serveRedirect(httpsPort int) (*http.Server, error) {
+	addr := formatBindAddr(gs.BindAddr, gs.tlsCfg.RedirectPort)
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen redirect: %w", err)
 	}
-	return err
+
+	svr := &http.Server{Handler: redirectHandler(httpsPort), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := svr.Serve(lis); err != nil && err != http.ErrServerClosed {
+			gs.logger.Errorln("redirect server stopped:", err)
+		}
+	}()
+
+	gs.logger.Infof("redirect %s to https...", lis.Addr().String())
+	return svr, nil
 }
 
-func getPort(lis net.Listener) int {
-	addr := lis.Addr()
-	tcp, _ := net.ResolveTCPAddr(addr.Network(), addr.String())
-	return tcp.Port
+func (gs *ginService) setStarted(err error) {
+	gs.startedOnce.Do(func() {
+		gs.mu.Lock()
+		gs.startErr = err
+		gs.mu.Unlock()
+		close(gs.started)
+	})
 }
 
+// WaitStarted blocks until the server listens, or returns the error of Run
+// if it failed before listening
+func (gs *ginService) WaitStarted() error {
+	<-gs.started
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.startErr
+}
+
+// Port blocks until the server listens and returns its port,
+// the actual one if it is configured with port 0
 func (gs *ginService) Port() int {
+	<-gs.started
+
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 	return gs.Config.Port
 }
 
 func (gs *ginService) Stop() <-chan bool {
+	gs.mu.Lock()
+	svr, draining := gs.svr, gs.shutdownDone != nil
+	gs.stopped = true
+	gs.mu.Unlock()
+
 	c := make(chan bool)
 
 	go func() {
-		if gs.svr != nil {
-			_ = gs.svr.Shutdown(context.Background())
+		if svr != nil {
+			if draining {
+				// close connections left by Drain
+				_ = svr.Close()
+			} else {
+				_ = svr.Shutdown(context.Background())
+			}
 		}
 		c <- true
 	}()
 	return c
 }
 
+// StopAccepting closes the listener, in-flight requests keep running until Drain
+func (gs *ginService) StopAccepting(ctx context.Context) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.svr == nil || gs.shutdownDone != nil {
+		return nil
+	}
+
+	svr, done := gs.svr, make(chan error, 1)
+	go func() { done <- svr.Shutdown(ctx) }()
+	gs.shutdownDone = done
+
+	return nil
+}
+
+// Drain waits until in-flight requests are done
+func (gs *ginService) Drain(ctx context.Context) error {
+	gs.mu.Lock()
+	done := gs.shutdownDone
+	gs.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (gs *ginService) URI() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 	return formatBindAddr(gs.BindAddr, gs.Config.Port)
 }
 
@@ -191,16 +566,83 @@ func (gs *ginService) AddHandler(hdl func(*gin.Engine)) {
 	gs.handlers = append(gs.handlers, hdl)
 }
 
+// AddSystemHandler adds handlers mounted whenever the server runs.
+// Unlike AddHandler, it does not enable the server
+func (gs *ginService) AddSystemHandler(hdl func(*gin.Engine)) {
+	gs.systemHandlers = append(gs.systemHandlers, hdl)
+}
+
+// Reload restarts the running server with config, it returns once the server listens
+// with config or failed to. On failure the server keeps running with its old config
 func (gs *ginService) Reload(config Config) error {
-	gs.Config = config
-	<-gs.Stop()
-	return gs.Run()
+	gs.mu.Lock()
+	if !gs.running || gs.restart != nil {
+		gs.mu.Unlock()
+		return errors.New("gin server is not running")
+	}
+
+	req := &restart{cfg: config, done: make(chan error, 1)}
+	svr := gs.svr
+	gs.restart = req
+	gs.mu.Unlock()
+
+	// Run listens with config once the old server is shut down
+	if err := svr.Shutdown(context.Background()); err != nil {
+		return err
+	}
+
+	return <-req.done
+}
+
+// Reconfigure restarts the server if the addresses in fs changed.
+// The server keeps running with the old config if it can't listen on the new address
+func (gs *ginService) Reconfigure(fs *flag.FlagSet) error {
+	if !gs.isEnabled || !gs.IsRunning() {
+		return nil
+	}
+
+	gs.mu.Lock()
+	listenCfg := gs.listenCfg
+	gs.mu.Unlock()
+
+	newCfg := listenCfg
+	next := flag.NewFlagSet(gs.Name(), flag.ContinueOnError)
+	newCfg.initFlags(next)
+	if err := flagset.Copy(next, fs); err != nil {
+		return err
+	}
+
+	if newCfg == listenCfg {
+		return nil
+	}
+
+	addr := newCfg.Listen
+	if addr == "" {
+		addr = formatBindAddr(newCfg.BindAddr, newCfg.Port)
+	}
+	gs.logger.Infof("restarting gin server on %s...", addr)
+
+	return gs.Reload(newCfg)
+}
+
+// Scheme returns https if the server serves TLS, otherwise http
+func (gs *ginService) Scheme() string {
+	if gs.tlsCfg.enabled() {
+		return "https"
+	}
+	return "http"
 }
 
+func (gs *ginService) isGinService() {}
+
 func (gs *ginService) GetConfig() Config {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 	return gs.Config
 }
 
 func (gs *ginService) IsRunning() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 	return gs.svr != nil
 }
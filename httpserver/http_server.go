@@ -29,9 +29,17 @@ type myHttpServer struct {
 }
 
 func (srv *myHttpServer) Serve(lis net.Listener) error {
-	return srv.Server.Serve(tcpKeepAliveListener{lis.(*net.TCPListener)})
+	return srv.Server.Serve(keepAlive(lis))
 }
 
 func (srv *myHttpServer) ServeTLS(lis net.Listener, certFile, keyFile string) error {
-	return srv.Server.ServeTLS(tcpKeepAliveListener{lis.(*net.TCPListener)}, certFile, keyFile)
+	return srv.Server.ServeTLS(keepAlive(lis), certFile, keyFile)
+}
+
+// keepAlive sets TCP keep-alive on TCP listeners, others (Ex: unix socket) are kept as is
+func keepAlive(lis net.Listener) net.Listener {
+	if tcp, ok := lis.(*net.TCPListener); ok {
+		return tcpKeepAliveListener{tcp}
+	}
+	return lis
 }
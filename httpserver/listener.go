@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// first file descriptor passed by systemd socket activation
+	listenFDsStart = 3
+)
+
+var (
+	// files of inherited sockets, they are kept open for the life of the process
+	// so the server listens on them again when it restarts
+	inheritedFiles   = map[int]*os.File{}
+	inheritedFilesMu sync.Mutex
+)
+
+// ListenAddr is an address the gin server listens on, see ParseListenAddrs
+type ListenAddr struct {
+	// tcp, unix or fd
+	Network string
+	// host:port for tcp, path for unix, file descriptor for fd
+	Address string
+}
+
+func (a ListenAddr) String() string {
+	return a.Network + "://" + a.Address
+}
+
+// ParseListenAddrs parses comma-separated addresses:
+//   - tcp://host:port or host:port. Ex: :3000, tcp://127.0.0.1:9090
+//   - unix:///path/to/file.sock
+//   - fd://3: an inherited file descriptor
+//   - systemd: all file descriptors passed by systemd socket activation (LISTEN_FDS)
+func ParseListenAddrs(spec string) ([]ListenAddr, error) {
+	var addrs []ListenAddr
+
+	for _, s := range strings.Split(spec, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		switch {
+		case s == "systemd" || s == "systemd://":
+			fds, err := systemdFDs()
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, fds...)
+
+		case strings.HasPrefix(s, "unix://"):
+			addrs = append(addrs, ListenAddr{Network: "unix", Address: strings.TrimPrefix(s, "unix://")})
+
+		case strings.HasPrefix(s, "fd://"):
+			fd := strings.TrimPrefix(s, "fd://")
+			if _, err := strconv.Atoi(fd); err != nil {
+				return nil, fmt.Errorf("invalid listen address %q: %w", s, err)
+			}
+			addrs = append(addrs, ListenAddr{Network: "fd", Address: fd})
+
+		default:
+			addr := strings.TrimPrefix(s, "tcp://")
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return nil, fmt.Errorf("invalid listen address %q: %w", s, err)
+			}
+			addrs = append(addrs, ListenAddr{Network: "tcp", Address: addr})
+		}
+	}
+
+	return addrs, nil
+}
+
+// systemdFDs returns file descriptors passed to this process by systemd
+func systemdFDs() ([]ListenAddr, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("no socket passed by systemd to this process")
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("no socket passed by systemd to this process")
+	}
+
+	addrs := make([]ListenAddr, 0, n)
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		addrs = append(addrs, ListenAddr{Network: "fd", Address: strconv.Itoa(fd)})
+	}
+
+	return addrs, nil
+}
+
+// listen opens a listener on addr. A stale unix socket file is removed first
+func listen(addr ListenAddr) (net.Listener, error) {
+	switch addr.Network {
+	case "unix":
+		if info, err := os.Stat(addr.Address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if conn, err := net.Dial("unix", addr.Address); err == nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("unix socket %s is in use", addr.Address)
+			}
+			_ = os.Remove(addr.Address)
+		}
+		return net.Listen("unix", addr.Address)
+
+	case "fd":
+		fd, _ := strconv.Atoi(addr.Address)
+		f := inheritedFile(fd)
+		if f == nil {
+			return nil, fmt.Errorf("invalid file descriptor %d", fd)
+		}
+
+		// the listener has a duplicate of the file descriptor
+		return net.FileListener(f)
+	}
+
+	return net.Listen("tcp", addr.Address)
+}
+
+// inheritedFile returns the file of an inherited file descriptor, opened once
+func inheritedFile(fd int) *os.File {
+	inheritedFilesMu.Lock()
+	defer inheritedFilesMu.Unlock()
+
+	if f, ok := inheritedFiles[fd]; ok {
+		return f
+	}
+
+	f := os.NewFile(uintptr(fd), "listener-"+strconv.Itoa(fd))
+	if f != nil {
+		inheritedFiles[fd] = f
+	}
+	return f
+}
+
+// listenAll opens listeners on all addresses, they are all closed if one fails
+func listenAll(addrs []ListenAddr) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+
+	for _, addr := range addrs {
+		lis, err := listen(addr)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("failed to listen %s: %w", addr, err)
+		}
+		listeners = append(listeners, lis)
+	}
+
+	return listeners, nil
+}
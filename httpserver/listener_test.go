@@ -0,0 +1,214 @@
+package httpserver
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListenAddrs(t *testing.T) {
+	addrs, err := ParseListenAddrs(":3000, tcp://127.0.0.1:9090,unix:///run/app.sock,fd://3")
+	require.NoError(t, err)
+	assert.Equal(t, []ListenAddr{
+		{Network: "tcp", Address: ":3000"},
+		{Network: "tcp", Address: "127.0.0.1:9090"},
+		{Network: "unix", Address: "/run/app.sock"},
+		{Network: "fd", Address: "3"},
+	}, addrs)
+
+	_, err = ParseListenAddrs("localhost")
+	assert.Error(t, err)
+
+	_, err = ParseListenAddrs("fd://stdin")
+	assert.Error(t, err)
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+	_, err = ParseListenAddrs("systemd")
+	assert.Error(t, err, "sockets are passed to another process")
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	addrs, err = ParseListenAddrs("systemd")
+	require.NoError(t, err)
+	assert.Equal(t, []ListenAddr{{Network: "fd", Address: "3"}, {Network: "fd", Address: "4"}}, addrs)
+}
+
+func startListenServer(t *testing.T, listen string) *ginService {
+	logger.InitServLogger(false)
+	gin.SetMode(gin.TestMode)
+
+	gs := New("test", "")
+	gs.noLogger = true
+	gs.Listen = listen
+	gs.AddHandler(func(engine *gin.Engine) {
+		engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- gs.Run() }()
+	gs.Port()
+
+	t.Cleanup(func() {
+		<-gs.Stop()
+		assert.NoError(t, <-errChan)
+	})
+
+	return gs
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMultipleListeners(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+
+	// an inherited socket, Ex: passed by systemd or the previous process
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f, err := inherited.(*net.TCPListener).File()
+	require.NoError(t, err)
+	_ = inherited.Close()
+	defer f.Close()
+
+	gs := startListenServer(t, fmt.Sprintf("127.0.0.1:0,unix://%s,fd://%d", sock, f.Fd()))
+
+	// the first TCP listener is the primary one
+	assert.NotZero(t, gs.Port())
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", gs.Port()), gs.URI())
+	assert.Equal(t, "pong", get(t, http.DefaultClient, fmt.Sprintf("http://%s/ping", gs.URI())))
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	assert.Equal(t, "pong", get(t, unixClient, "http://unix/ping"))
+
+	assert.Equal(t, "pong", get(t, http.DefaultClient, fmt.Sprintf("http://%s/ping", inherited.Addr())))
+}
+
+func TestListenFailure(t *testing.T) {
+	logger.InitServLogger(false)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	sock := filepath.Join(t.TempDir(), "app.sock")
+
+	gs := New("test", "")
+	gs.Listen = fmt.Sprintf("unix://%s,%s", sock, busy.Addr())
+	gs.AddHandler(func(engine *gin.Engine) {})
+
+	assert.Error(t, gs.Run())
+
+	// the unix socket opened before the failure is closed
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStopBeforeRun(t *testing.T) {
+	logger.InitServLogger(false)
+
+	gs := New("test", "")
+	gs.Listen = "127.0.0.1:0"
+	gs.AddHandler(func(engine *gin.Engine) {})
+
+	<-gs.Stop()
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- gs.Run() }()
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		<-gs.Stop()
+		t.Fatal("the server serves after Stop")
+	}
+	assert.False(t, gs.IsRunning())
+}
+
+// listenFlags is a flag set parsed again on reload with a new gin-listen
+func listenFlags(listen string) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("gin-listen", listen, "")
+	return fs
+}
+
+func TestReconfigure(t *testing.T) {
+	gs := startListenServer(t, "127.0.0.1:0")
+	oldURI := gs.URI()
+
+	// flags are parsed again with a new address
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	require.NoError(t, gs.Reconfigure(listenFlags(fmt.Sprintf("unix://%s,127.0.0.1:0", sock))))
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	assert.Equal(t, "pong", get(t, unixClient, "http://unix/ping"))
+	assert.NotEqual(t, oldURI, gs.URI())
+	assert.Equal(t, "pong", get(t, http.DefaultClient, fmt.Sprintf("http://%s/ping", gs.URI())))
+}
+
+func TestReconfigureFailure(t *testing.T) {
+	gs := startListenServer(t, "127.0.0.1:0")
+	oldURI := gs.URI()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	assert.Error(t, gs.Reconfigure(listenFlags(busy.Addr().String())))
+
+	// the server keeps serving with its old config
+	assert.Equal(t, oldURI, gs.URI())
+	assert.Equal(t, "pong", get(t, http.DefaultClient, fmt.Sprintf("http://%s/ping", gs.URI())))
+	assert.Equal(t, "127.0.0.1:0", gs.GetConfig().Listen)
+	assert.NoError(t, gs.Reconfigure(listenFlags("127.0.0.1:0")), "the old config is not restarted again")
+}
+
+func TestReconfigureInheritedSocket(t *testing.T) {
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f, err := inherited.(*net.TCPListener).File()
+	require.NoError(t, err)
+	_ = inherited.Close()
+	defer f.Close()
+
+	listen := fmt.Sprintf("fd://%d", f.Fd())
+	gs := startListenServer(t, listen)
+	url := fmt.Sprintf("http://%s/ping", inherited.Addr())
+	assert.Equal(t, "pong", get(t, http.DefaultClient, url))
+
+	// the inherited socket is still open when the server listens again
+	require.NoError(t, gs.Reconfigure(listenFlags(listen+",127.0.0.1:0")))
+	assert.Equal(t, "pong", get(t, http.DefaultClient, url))
+
+	// and when it falls back to it after a failed restart
+	assert.Error(t, gs.Reconfigure(listenFlags(listen+",unix:///no/such/dir/app.sock")))
+	assert.Equal(t, "pong", get(t, http.DefaultClient, url))
+}
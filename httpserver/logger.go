@@ -42,6 +42,7 @@ func Logger(log logger.Logger) gin.HandlerFunc {
 			"referer":    referer,
 			"dataLength": dataLength,
 			"userAgent":  clientUserAgent,
+			"request_id": logger.RequestIDFromContext(c.Request.Context()),
 		})
 
 		if len(c.Errors) > 0 {
@@ -0,0 +1,42 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/httpserver/middleware"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// fieldsLogger records fields of the access log
+type fieldsLogger struct {
+	logger.Logger
+	fields logger.Fields
+}
+
+func (l *fieldsLogger) Withs(fields logger.Fields) logger.Logger {
+	l.fields = fields
+	return l
+}
+
+func (l *fieldsLogger) Info(...interface{}) {}
+
+func TestLoggerRequestID(t *testing.T) {
+	logger.InitServLogger(false)
+	gin.SetMode(gin.TestMode)
+
+	l := &fieldsLogger{Logger: logger.GetCurrent().GetLogger("gin")}
+	router := gin.New()
+	router.Use(middleware.RequestID(), Logger(l))
+	router.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "req-1", l.fields[middleware.RequestIDKey])
+	assert.Equal(t, http.StatusOK, l.fields["statusCode"])
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+)
+
+const (
+	// errors are rendered as AppError JSON
+	ErrorFormatJSON = "json"
+	// errors are rendered as RFC 7807 problem details, see sdkcm.Problem
+	ErrorFormatProblem = "problem"
+
+	// key of the error format in gin.Context
+	errorFormatKey = "error_format"
+)
+
+// ValidateErrorFormat returns an error if format is not a known error format
+func ValidateErrorFormat(format string) error {
+	switch format {
+	case ErrorFormatJSON, ErrorFormatProblem:
+		return nil
+	}
+	return fmt.Errorf("invalid error format %q, it must be %s or %s", format, ErrorFormatJSON, ErrorFormatProblem)
+}
+
+// ErrorFormat sets the format of errors rendered by AbortWithAppError when clients don't ask
+// for one with the Accept header
+func ErrorFormat(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(errorFormatKey, format)
+		c.Next()
+	}
+}
+
+// AbortWithAppError writes err with its status code and stops the handler chain.
+// The trace ID of err is the ID of the request, so clients can report it.
+// err is rendered as problem details if the client accepts application/problem+json
+// or if it is the format set by ErrorFormat, as AppError JSON otherwise
+func AbortWithAppError(c *gin.Context, err *sdkcm.AppError) {
+	if err.TraceID == "" {
+		err.TraceID = GetRequestID(c)
+	}
+
+	if errorFormat(c) != ErrorFormatProblem {
+		c.AbortWithStatusJSON(err.StatusCode, err)
+		return
+	}
+
+	c.Abort()
+	c.Render(err.StatusCode, problemRender{problem: err.Problem(c.Request.URL.Path)})
+}
+
+// errorFormat negotiates the format with the Accept header of the request:
+// the media type with the highest q-value wins, the first one listed on a tie
+func errorFormat(c *gin.Context) string {
+	format, bestQ := "", 0.0
+
+	for _, accepted := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		if q <= bestQ {
+			continue
+		}
+
+		switch mediaType {
+		case sdkcm.ContentTypeProblem:
+			format, bestQ = ErrorFormatProblem, q
+		case "application/json":
+			format, bestQ = ErrorFormatJSON, q
+		}
+	}
+
+	if format != "" {
+		return format
+	}
+
+	if format := c.GetString(errorFormatKey); format != "" {
+		return format
+	}
+	return ErrorFormatJSON
+}
+
+// problemRender renders problem details with their media type, gin only has application/json
+type problemRender struct {
+	problem *sdkcm.Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	b, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", sdkcm.ContentTypeProblem)
+}
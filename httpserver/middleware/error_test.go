@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbortWithAppErrorFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name, format, accept, contentType string
+	}{
+		{name: "default", contentType: "application/json"},
+		{name: "accept problem", accept: "application/problem+json", contentType: sdkcm.ContentTypeProblem},
+		{name: "accept problem with others", accept: "text/html, application/problem+json;q=0.9", contentType: sdkcm.ContentTypeProblem},
+		{name: "server format", format: ErrorFormatProblem, accept: "*/*", contentType: sdkcm.ContentTypeProblem},
+		{name: "accept json over server format", format: ErrorFormatProblem, accept: "application/json", contentType: "application/json"},
+		{name: "accept by q-value", accept: "application/json;q=0.1, application/problem+json", contentType: sdkcm.ContentTypeProblem},
+		{name: "accept first on same q-value", accept: "application/json;q=0.5, application/problem+json;q=0.5", contentType: "application/json"},
+		{name: "accept not json", format: ErrorFormatProblem, accept: "application/json;q=0", contentType: sdkcm.ContentTypeProblem},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			engine := gin.New()
+			engine.Use(ErrorFormat(tc.format))
+			engine.GET("/orders/:id", func(c *gin.Context) {
+				AbortWithAppError(c, sdkcm.ErrEntityNotFound("Order", errors.New("no rows")))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+			req.Header.Set("Accept", tc.accept)
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Header().Get("Content-Type"), tc.contentType)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, "ErrOrderNotFound", body["error_key"])
+
+			if tc.contentType == sdkcm.ContentTypeProblem {
+				assert.Equal(t, "about:blank", body["type"])
+				assert.Equal(t, "/orders/1", body["instance"])
+				assert.Equal(t, "order not found", body["detail"])
+			} else {
+				assert.Equal(t, "order not found", body["message"])
+			}
+		})
+	}
+}
+
+func TestRecoverValidationErrors(t *testing.T) {
+	engine, _ := newRecoverEngine(t, "", func(c *gin.Context) {
+		var body struct {
+			Name  string `json:"name" binding:"required"`
+			Email string `json:"email" binding:"required,email"`
+		}
+
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(`{"email":"not-an-email"}`))
+	req.Header.Set("Accept", sdkcm.ContentTypeProblem)
+	engine.ServeHTTP(rec, req)
+
+	var problem sdkcm.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "ErrValidation", problem.ErrorKey)
+	assert.Equal(t, []sdkcm.FieldError{
+		{Field: "Name", Message: "Name failed on the 'required' rule", Rule: "required"},
+		{Field: "Email", Message: "Email failed on the 'email' rule", Rule: "email"},
+	}, problem.Errors)
+}
+
+func TestValidateErrorFormat(t *testing.T) {
+	assert.NoError(t, ValidateErrorFormat(ErrorFormatJSON))
+	assert.NoError(t, ValidateErrorFormat(ErrorFormatProblem))
+	assert.Error(t, ValidateErrorFormat("xml"))
+}
@@ -1,55 +1,143 @@
 package middleware
 
-//func Recover(sc ServiceContext) gin.HandlerFunc {
-//	return func(c *gin.Context) {
-//		logger := sc.Logger("service")
-//		lvLogger := logger.GetLevel()
-//
-//		defer func() {
-//			if err := recover(); err != nil {
-//				c.Header("Content-Type", "application/json")
-//				didFireError := false
-//
-//				if appErr, ok := err.(sdkcm.AppError); ok {
-//					appErr.RootCause = appErr.RootError()
-//
-//					if appErr.RootCause != nil {
-//						appErr.Log = appErr.RootCause.Error()
-//					}
-//
-//					logger.Errorln("App Error: ", appErr)
-//
-//					c.AbortWithStatusJSON(appErr.StatusCode, appErr)
-//
-//					if lvLogger == logrus.TraceLevel.String() {
-//						panic(err)
-//					}
-//				} else {
-//					var appErr sdkcm.AppError
-//
-//					if e, ok := err.(error); ok {
-//						appErr = sdkcm.AppError{StatusCode: http.StatusInternalServerError, Message: "internal server error"}
-//						logger.Errorln(e.Error())
-//
-//						c.AbortWithStatusJSON(appErr.StatusCode, appErr)
-//						didFireError = true
-//						panic(err)
-//					} else {
-//						appErr = sdkcm.AppError{StatusCode: http.StatusInternalServerError, Message: fmt.Sprintf("%s", err)}
-//						logger.Errorln(fmt.Sprintf("%s", err))
-//
-//						c.AbortWithStatusJSON(appErr.StatusCode, appErr)
-//						didFireError = true
-//						panic(err)
-//					}
-//				}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+)
+
+// env of the service where Log of errors is hidden from clients
+const prdEnv = "prd"
+
+// Recover renders panics and errors added with c.Error as AppError with their status code, see AbortWithAppError:
 //
-//				if lvLogger == logrus.TraceLevel.String() && !didFireError {
-//					panic(err)
-//				}
-//			}
-//		}()
+//	panic(sdkcm.ErrDB(err))
+//	_ = c.Error(sdkcm.ErrInvalidRequest(err))
 //
-//		c.Next()
-//	}
-//}
+// Binding errors of c.ShouldBind are validation errors with the invalid fields,
+// other plain errors and panics are internal errors. In prd env, Log of errors is not rendered.
+// Errors are logged with the logger of the request, 5xx ones are reported to Sentry
+func Recover(env string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if isBrokenPipeError(r) {
+					// the client is gone, there is no one to render to
+					logger.FromContext(c.Request.Context()).Warnln("connection is broken:", r)
+					c.Abort()
+					return
+				}
+
+				handleError(c, env, toAppError(r), r, debug.Stack())
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		handleError(c, env, toAppError(c.Errors.Last().Err), nil, nil)
+	}
+}
+
+// toAppError returns a copy of the AppError in v, or an internal error wrapping v
+func toAppError(v interface{}) *sdkcm.AppError {
+	var appErr *sdkcm.AppError
+
+	switch e := v.(type) {
+	case sdkcm.AppError:
+		appErr = &e
+	case error:
+		var fieldErrs validator.ValidationErrors
+
+		if errors.As(e, &appErr) {
+			copied := *appErr
+			appErr = &copied
+		} else if errors.As(e, &fieldErrs) {
+			appErr = validationError(fieldErrs)
+		} else {
+			appErr = sdkcm.ErrInternal(e)
+		}
+	default:
+		appErr = sdkcm.ErrInternal(fmt.Errorf("%v", v))
+	}
+
+	if appErr.StatusCode == 0 {
+		appErr.StatusCode = http.StatusInternalServerError
+	}
+
+	if appErr.RootErr == nil {
+		// Error() of AppError needs a root error
+		appErr.RootErr = errors.New(appErr.Message)
+	}
+
+	return appErr
+}
+
+// validationError lists fields failing the binding rules of gin
+func validationError(errs validator.ValidationErrors) *sdkcm.AppError {
+	fields := make([]sdkcm.FieldError, len(errs))
+	for i, fe := range errs {
+		fields[i] = sdkcm.FieldError{
+			Field:   fe.Field(),
+			Message: fmt.Sprintf("%s failed on the '%s' rule", fe.Field(), fe.Tag()),
+			Rule:    fe.Tag(),
+		}
+	}
+
+	return sdkcm.ErrValidation(fields...)
+}
+
+func handleError(c *gin.Context, env string, appErr *sdkcm.AppError, recovered interface{}, stack []byte) {
+	log := logger.FromContext(c.Request.Context()).Withs(logger.Fields{
+		"method":    c.Request.Method,
+		"path":      c.Request.URL.Path,
+		"status":    appErr.StatusCode,
+		"error_key": appErr.Key,
+	})
+
+	if appErr.StatusCode >= http.StatusInternalServerError {
+		if stack != nil {
+			log = log.With("stack", string(stack))
+		}
+		log.Errorln(appErr.Message+":", appErr.RootError())
+
+		reportToSentry(c, appErr, recovered)
+	} else {
+		log.Debugln(appErr.Message+":", appErr.RootError())
+	}
+
+	if env == prdEnv {
+		appErr.Log = ""
+	}
+
+	AbortWithAppError(c, appErr)
+}
+
+// reportToSentry uses the hub of the request set by the Sentry middleware, or the current one
+func reportToSentry(c *gin.Context, appErr *sdkcm.AppError, recovered interface{}) {
+	hub := GetHubFromContext(c)
+	if hub == nil {
+		hub = sentry.GetHubFromContext(c.Request.Context())
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	if recovered != nil {
+		hub.RecoverWithContext(context.WithValue(c.Request.Context(), sentry.RequestContextKey, c.Request), appErr)
+		return
+	}
+
+	hub.CaptureException(appErr)
+}
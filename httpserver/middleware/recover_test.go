@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *fakeTransport) Flush(timeout time.Duration) bool       { return true }
+func (t *fakeTransport) Configure(options sentry.ClientOptions) {}
+func (t *fakeTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *fakeTransport) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.events)
+}
+
+func newRecoverEngine(t *testing.T, env string, handler gin.HandlerFunc) (*gin.Engine, *fakeTransport) {
+	gin.SetMode(gin.TestMode)
+
+	transport := &fakeTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.Use(RequestID(), func(c *gin.Context) {
+		c.Set(valuesKey, sentry.NewHub(client, sentry.NewScope()))
+	}, Recover(env))
+	engine.GET("/", handler)
+
+	return engine, transport
+}
+
+func serve(engine *gin.Engine) (*httptest.ResponseRecorder, sdkcm.AppError) {
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body sdkcm.AppError
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+
+	return rec, body
+}
+
+func TestRecover(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		env     string
+		handler gin.HandlerFunc
+		status  int
+		key     string
+		log     string
+		reports int
+	}{
+		{
+			name:    "panic app error",
+			handler: func(c *gin.Context) { panic(sdkcm.ErrDB(errors.New("connection refused"))) },
+			status:  http.StatusInternalServerError, key: "DB_ERROR", log: "connection refused", reports: 1,
+		},
+		{
+			name: "panic app error value",
+			handler: func(c *gin.Context) {
+				panic(sdkcm.AppError{StatusCode: http.StatusConflict, Message: "conflict", Key: "ErrConflict"})
+			},
+			status: http.StatusConflict, key: "ErrConflict",
+		},
+		{
+			name:    "panic plain error",
+			handler: func(c *gin.Context) { panic(errors.New("nil map")) },
+			status:  http.StatusInternalServerError, key: "ErrInternal", log: "nil map", reports: 1,
+		},
+		{
+			name:    "panic value",
+			handler: func(c *gin.Context) { panic("boom") },
+			status:  http.StatusInternalServerError, key: "ErrInternal", log: "boom", reports: 1,
+		},
+		{
+			name: "context error",
+			handler: func(c *gin.Context) {
+				_ = c.Error(sdkcm.ErrInvalidRequest(errors.New("missing name")))
+			},
+			status: http.StatusBadRequest, key: "ErrInvalidRequest", log: "missing name",
+		},
+		{
+			name:    "log hidden in prd",
+			env:     prdEnv,
+			handler: func(c *gin.Context) { _ = c.Error(errors.New("dial tcp 10.0.0.1:5432")) },
+			status:  http.StatusInternalServerError, key: "ErrInternal", reports: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine, transport := newRecoverEngine(t, tc.env, tc.handler)
+
+			rec, body := serve(engine)
+
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, tc.status, body.StatusCode)
+			assert.Equal(t, tc.key, body.Key)
+			assert.Equal(t, tc.log, body.Log)
+			assert.Equal(t, rec.Header().Get(RequestIDHeader), body.TraceID)
+			assert.Equal(t, tc.reports, transport.count())
+		})
+	}
+}
+
+func TestRecoverKeepsWrittenResponse(t *testing.T) {
+	engine, transport := newRecoverEngine(t, "", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "queued")
+		_ = c.Error(errors.New("cannot notify"))
+	})
+
+	rec, _ := serve(engine)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "queued", rec.Body.String())
+	assert.Zero(t, transport.count())
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequestIDEngine(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(RequestID())
+	engine.GET("/", handler)
+
+	return engine
+}
+
+func TestRequestID(t *testing.T) {
+	var fromContext string
+	engine := newRequestIDEngine(func(c *gin.Context) {
+		fromContext = logger.RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		name, header string
+		keep         bool
+	}{
+		{name: "from client", header: "req-1", keep: true},
+		{name: "generated", header: ""},
+		{name: "invalid", header: "req 1\x01"},
+		{name: "too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tc.header)
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, id, fromContext)
+
+			if tc.keep {
+				assert.Equal(t, tc.header, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+		})
+	}
+}
+
+func TestAbortWithAppErrorSetsTraceID(t *testing.T) {
+	engine := newRequestIDEngine(func(c *gin.Context) {
+		AbortWithAppError(c, sdkcm.ErrInvalidRequest(errors.New("missing name")))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"trace_id":"req-1"`)
+}
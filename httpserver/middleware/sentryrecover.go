@@ -52,6 +52,9 @@ func (h *handler) handle(ctx *gin.Context) {
 		hub = sentry.CurrentHub().Clone()
 	}
 	hub.Scope().SetRequest(ctx.Request)
+	if id := GetRequestID(ctx); id != "" {
+		hub.Scope().SetTag(RequestIDKey, id)
+	}
 	ctx.Set(valuesKey, hub)
 	defer h.recoverWithSentry(hub, ctx.Request)
 	ctx.Next()
@@ -0,0 +1,224 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig of the gin server, it serves HTTPS if the cert and key files are set
+type TLSConfig struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	MinVersion     string
+	ReloadInterval time.Duration
+	RedirectPort   int
+}
+
+func (c TLSConfig) enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+func (c *TLSConfig) initFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CertFile, "gin-tls-cert-file", "", "Certificate file to serve HTTPS, plain HTTP if empty")
+	fs.StringVar(&c.KeyFile, "gin-tls-key-file", "", "Key file of the certificate to serve HTTPS")
+	fs.StringVar(&c.ClientCAFile, "gin-tls-client-ca-file", "",
+		"CA file to verify client certificates, clients must have one signed by it (mutual TLS)")
+	fs.StringVar(&c.MinVersion, "gin-tls-min-version", "1.2", "Minimum TLS version: 1.0 | 1.1 | 1.2 | 1.3")
+	fs.DurationVar(&c.ReloadInterval, "gin-tls-reload-interval", time.Minute,
+		"Interval to reload certificate and client CA files if they change, 0 to disable")
+	fs.IntVar(&c.RedirectPort, "gin-tls-redirect-port", 0,
+		"Port of a plain HTTP listener redirecting to HTTPS. Ex: 80, 0 to disable")
+}
+
+// certReloader serves the certificate and client CAs loaded from files
+// and loads them again when the files change
+type certReloader struct {
+	cfg    TLSConfig
+	logger logger.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+}
+
+func newCertReloader(cfg TLSConfig, l logger.Logger) (*certReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("both gin-tls-cert-file and gin-tls-key-file must be set")
+	}
+
+	if _, ok := tlsVersions[cfg.MinVersion]; !ok {
+		return nil, fmt.Errorf("invalid gin-tls-min-version %q", cfg.MinVersion)
+	}
+
+	r := &certReloader{cfg: cfg, logger: l}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// load reads the files and keeps the old certificate if they are invalid
+func (r *certReloader) load() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading tls certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		ca, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("loading tls client ca: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("loading tls client ca: no certificate in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.modTime = &cert, clientCAs, modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// lastModified returns the latest modification time of the files
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+
+	for _, f := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if f == "" {
+			continue
+		}
+
+		info, err := os.Stat(f)
+		if err != nil {
+			return latest, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// reloadIfModified loads the files again if one of them changes
+func (r *certReloader) reloadIfModified() {
+	modTime, err := r.lastModified()
+	if err != nil {
+		r.logger.Errorln("cannot check tls files:", err)
+		return
+	}
+
+	r.mu.RLock()
+	changed := !modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		r.logger.Errorln("cannot reload tls files, keep the old ones:", err)
+		return
+	}
+
+	r.logger.Infoln("tls certificate is reloaded")
+}
+
+// watch reloads the files every reload interval until stop is closed
+func (r *certReloader) watch(stop <-chan struct{}) {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.reloadIfModified()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) tlsConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tlsVersions[r.cfg.MinVersion],
+		GetCertificate: r.getCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if r.cfg.ClientCAFile == "" {
+		return cfg
+	}
+
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		c := cfg.Clone()
+		c.GetConfigForClient = nil
+		c.ClientCAs = r.clientCAs
+		return c, nil
+	}
+
+	return cfg
+}
+
+// redirectHandler redirects requests to the same URL with https on httpsPort
+func redirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host, _, err := net.SplitHostPort(req.Host)
+		if err != nil {
+			host = strings.Trim(req.Host, "[]")
+		}
+
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + req.URL.RequestURI()
+		http.Redirect(w, req, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,231 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue writes a certificate signed by the CA and its key to dir/name.crt and dir/name.key
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certFile, keyFile
+}
+
+func (ca *testCA) writeTo(t *testing.T, dir string) string {
+	file := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600))
+	return file
+}
+
+func startTLSServer(t *testing.T, cfg TLSConfig) int {
+	logger.InitServLogger(false)
+	gin.SetMode(gin.TestMode)
+
+	gs := New("test", "")
+	gs.noLogger = true
+	gs.Config = Config{BindAddr: "127.0.0.1"}
+	gs.tlsCfg = cfg
+	gs.AddHandler(func(engine *gin.Engine) {
+		engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- gs.Run() }()
+
+	t.Cleanup(func() {
+		<-gs.Stop()
+		assert.NoError(t, <-errChan)
+	})
+
+	assert.Equal(t, "https", gs.Scheme())
+	return gs.Port()
+}
+
+func TestTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+
+	port := startTLSServer(t, TLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		MinVersion:     "1.2",
+		ReloadInterval: 10 * time.Millisecond,
+	})
+
+	serial := func() int64 {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: ca.pool},
+			DisableKeepAlives: true,
+		}}
+
+		resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/ping", port))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(10), serial())
+
+	// a renewed certificate is served without restarting
+	time.Sleep(10 * time.Millisecond)
+	ca.issue(t, dir, "server", 11, x509.ExtKeyUsageServerAuth)
+
+	assert.Eventually(t, func() bool { return serial() == 11 }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", 20, x509.ExtKeyUsageClientAuth)
+
+	port := startTLSServer(t, TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: ca.writeTo(t, dir),
+		MinVersion:   "1.2",
+	})
+	url := fmt.Sprintf("https://127.0.0.1:%d/ping", port)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool}}}
+	_, err := client.Get(url)
+	assert.Error(t, err, "clients without certificate are rejected")
+
+	cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      ca.pool,
+		Certificates: []tls.Certificate{cert},
+	}}}
+
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCertReloaderConfig(t *testing.T) {
+	logger.InitServLogger(false)
+	l := logger.GetCurrent().GetLogger("gin")
+
+	_, err := newCertReloader(TLSConfig{CertFile: "server.crt", MinVersion: "1.2"}, l)
+	assert.Error(t, err, "key file is missing")
+
+	dir := t.TempDir()
+	certFile, keyFile := newTestCA(t).issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+
+	_, err = newCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.4"}, l)
+	assert.Error(t, err)
+
+	r, err := newCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"}, l)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), r.tlsConfig().MinVersion)
+
+	// an invalid renewed certificate keeps the old one
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+	old, _ := r.getCertificate(nil)
+	r.reloadIfModified()
+	current, _ := r.getCertificate(nil)
+	assert.Same(t, old, current)
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		host      string
+		httpsPort int
+		want      string
+	}{
+		{"example.com", 443, "https://example.com/users?page=2"},
+		{"example.com:80", 443, "https://example.com/users?page=2"},
+		{"example.com:8080", 8443, "https://example.com:8443/users?page=2"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users?page=2", nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+
+		redirectHandler(tt.httpsPort).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, tt.want, w.Header().Get("Location"))
+	}
+}
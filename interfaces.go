@@ -3,6 +3,9 @@
 package goservice
 
 import (
+	"context"
+	"flag"
+
 	"github.com/gin-gonic/gin"
 	"github.com/lequocbinh04/go-sdk/logger"
 )
@@ -44,20 +47,35 @@ type Service interface {
 	// Gin HTTP Server wrapper
 	HTTPServer() HttpServer
 	// Init with options, they can be db connections or
-	// anything the service need handle before starting
+	// anything the service need handle before starting.
+	// Components are started in dependency order, it returns an error
+	// if dependencies have a cycle or refer to a missing prefix,
+	// or if components declared by WithRequirements are not satisfied
 	Init() error
 	// Same Init but have prefix
 	InitPrefix(prefix ...string) error
+	// Stop init components with prefix in reverse dependency order within the shutdown timeout,
+	// ex: the ones started by InitPrefix. Unlike Stop, it can be called more than once
+	StopPrefix(prefix ...string) error
 	// This method returns service if it is registered on discovery
 	IsRegistered() bool
 	// Start service and its all component.
-	// It will be stopped if any service return error
+	// It will be stopped if any service return error.
+	// SIGHUP reloads config of Reloadable components.
+	// See WithOnStart and WithOnReady for hooks run by Start.
+	// It returns a *ShutdownTimeoutError if components did not stop in time
 	Start(exitCallback func()) error
-	// Stop service and its all component.
+	// Stop service and its all component within the shutdown timeout:
+	// components stop accepting connections, drain in-flight requests (see GracefulStopper),
+	// then sub services are stopped and init components in reverse dependency order.
+	// It is safe to call Stop more than once, Start returns after it
 	Stop()
 	// Method export all flags to std/terminal
-	// We might use: "> .env" to move its content .env file
+	// We might use: "> .env" to move its content .env file.
+	// With -outenv-format=yaml, it exports a sample yaml config file
 	OutEnv()
+	// Flags of the service and its components
+	Flags() *AppFlagSet
 
 	// Method to add some Runable into it
 	Add(opts ...Option) Service
@@ -77,14 +95,23 @@ type ServiceContext interface {
 // Runnable is an abstract object in SDK
 // Almost components are Runnable. SDK will manage their lifecycle
 // InitFlags -> Configure -> Run -> Stop
+// InitFlags registers flags on the flag set of the service,
+// see FromLegacy for components using the global flag.CommandLine
 type Runnable interface {
 	Name() string
-	InitFlags()
+	InitFlags(fs *flag.FlagSet)
 	Configure() error
 	Run() error
 	Stop() <-chan bool
 }
 
+// HealthChecker is an optional interface for Runnable components.
+// Health returns nil if the component (and the dependency behind it) is up.
+// The service aggregates it in /healthz and /readyz endpoints
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
 // GIN HTTP server for REST API
 type HttpServer interface {
 	Runnable
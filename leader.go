@@ -0,0 +1,131 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"flag"
+	"sync"
+)
+
+// LeaderElector is implemented by leader election components, see plugin/leader.
+// Only one instance of the service is leader at a time
+type LeaderElector interface {
+	IsLeader() bool
+	// OnElected adds a callback run when this instance becomes leader
+	OnElected(fn func())
+	// OnRevoked adds a callback run when this instance is no longer leader
+	OnRevoked(fn func())
+}
+
+// WithLeaderOnlyRunnable runs r only while this instance is leader of the
+// election component with leaderPrefix: r runs when it is elected and stops
+// when it is revoked. Ex: cron jobs, queue consumers which must not run twice
+func WithLeaderOnlyRunnable(leaderPrefix string, r Runnable) Option {
+	return func(s *service) {
+		s.subServices = append(s.subServices, &leaderOnlyRunnable{
+			sv:           s,
+			leaderPrefix: leaderPrefix,
+			runnable:     r,
+		})
+	}
+}
+
+type leaderOnlyRunnable struct {
+	sv           *service
+	leaderPrefix string
+	runnable     Runnable
+
+	// set by callbacks of the elector and by Stop
+	mu      sync.Mutex
+	leading bool
+	stopped bool
+
+	// serializes starting and stopping the runnable, it guards running
+	syncMu  sync.Mutex
+	running bool
+}
+
+func (l *leaderOnlyRunnable) Name() string {
+	return l.runnable.Name()
+}
+
+func (l *leaderOnlyRunnable) InitFlags(fs *flag.FlagSet) {
+	l.runnable.InitFlags(fs)
+}
+
+func (l *leaderOnlyRunnable) Configure() error {
+	return l.runnable.Configure()
+}
+
+// Run registers callbacks on the elector, the runnable is started by them
+func (l *leaderOnlyRunnable) Run() error {
+	elector, err := Get[LeaderElector](l.sv, l.leaderPrefix)
+	if err != nil {
+		return err
+	}
+
+	elector.OnElected(func() { l.setLeading(true) })
+	elector.OnRevoked(func() { l.setLeading(false) })
+
+	if elector.IsLeader() {
+		l.setLeading(true)
+	}
+
+	return nil
+}
+
+// setLeading starts or stops the runnable in background,
+// so callbacks don't block the election loop while it stops
+func (l *leaderOnlyRunnable) setLeading(leading bool) {
+	l.mu.Lock()
+	l.leading = leading
+	l.mu.Unlock()
+
+	go l.sync()
+}
+
+// sync starts the runnable if this instance leads and the service is not stopped,
+// otherwise it stops the runnable and waits until it is stopped
+func (l *leaderOnlyRunnable) sync() {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+
+	l.mu.Lock()
+	run := l.leading && !l.stopped
+	l.mu.Unlock()
+
+	logger := l.sv.Logger("service")
+
+	switch {
+	case run && !l.running:
+		l.running = true
+		logger.Infof("%s is started on the leader", l.runnable.Name())
+
+		go func() {
+			if err := l.runnable.Run(); err != nil {
+				logger.Errorf("%s stopped with error: %s", l.runnable.Name(), err)
+			}
+		}()
+	case !run && l.running:
+		l.running = false
+
+		<-l.runnable.Stop()
+		logger.Infof("%s is stopped, this instance is no longer leader", l.runnable.Name())
+	}
+}
+
+// Stop the runnable if it is running, it is not started again
+func (l *leaderOnlyRunnable) Stop() <-chan bool {
+	c := make(chan bool)
+
+	go func() {
+		l.mu.Lock()
+		l.stopped = true
+		l.mu.Unlock()
+
+		l.sync()
+		c <- true
+	}()
+
+	return c
+}
@@ -0,0 +1,149 @@
+package goservice
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeElector struct {
+	testComponent
+	isLeader  bool
+	onElected []func()
+	onRevoked []func()
+}
+
+func (e *fakeElector) Get() interface{}    { return e }
+func (e *fakeElector) IsLeader() bool      { return e.isLeader }
+func (e *fakeElector) OnElected(fn func()) { e.onElected = append(e.onElected, fn) }
+func (e *fakeElector) OnRevoked(fn func()) { e.onRevoked = append(e.onRevoked, fn) }
+
+func (e *fakeElector) setLeader(isLeader bool) {
+	e.isLeader = isLeader
+
+	callbacks := e.onRevoked
+	if isLeader {
+		callbacks = e.onElected
+	}
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+type workerRunnable struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func newWorkerRunnable() *workerRunnable {
+	return &workerRunnable{started: make(chan struct{}, 10), stopped: make(chan struct{}, 10)}
+}
+
+func (w *workerRunnable) Name() string            { return "worker" }
+func (w *workerRunnable) InitFlags(*flag.FlagSet) {}
+func (w *workerRunnable) Configure() error        { return nil }
+func (w *workerRunnable) Run() error {
+	w.started <- struct{}{}
+	return nil
+}
+func (w *workerRunnable) Stop() <-chan bool {
+	w.stopped <- struct{}{}
+	c := make(chan bool, 1)
+	c <- true
+	return c
+}
+
+// blockingRunnable stops when release is closed
+type blockingRunnable struct {
+	workerRunnable
+	release chan struct{}
+}
+
+func (w *blockingRunnable) Stop() <-chan bool {
+	w.stopped <- struct{}{}
+	c := make(chan bool)
+	go func() {
+		<-w.release
+		c <- true
+	}()
+	return c
+}
+
+func TestLeaderOnlyRunnable(t *testing.T) {
+	elector := &fakeElector{testComponent: testComponent{prefix: "leader"}}
+	worker := newWorkerRunnable()
+
+	sv := newTestService(
+		WithInitRunnable(elector),
+		WithLeaderOnlyRunnable("leader", worker),
+	)
+
+	r := sv.subServices[0]
+	assert.Equal(t, "worker", r.Name())
+	assert.NoError(t, r.Run())
+	assert.Len(t, worker.started, 0)
+
+	elector.setLeader(true)
+	<-worker.started
+
+	// elected twice without being revoked, it runs once
+	elector.setLeader(true)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, worker.started, 0)
+
+	elector.setLeader(false)
+	<-worker.stopped
+
+	elector.setLeader(true)
+	<-worker.started
+
+	<-r.Stop()
+	assert.Len(t, worker.stopped, 1)
+	<-worker.stopped
+
+	// not started again once the service stops
+	elector.setLeader(false)
+	elector.setLeader(true)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, worker.started, 0)
+	assert.Len(t, worker.stopped, 0)
+}
+
+func TestLeaderOnlyRunnableRevokedWhileStopping(t *testing.T) {
+	elector := &fakeElector{testComponent: testComponent{prefix: "leader"}}
+	worker := &blockingRunnable{workerRunnable: *newWorkerRunnable(), release: make(chan struct{})}
+
+	sv := newTestService(
+		WithInitRunnable(elector),
+		WithLeaderOnlyRunnable("leader", worker),
+	)
+
+	r := sv.subServices[0]
+	assert.NoError(t, r.Run())
+
+	elector.setLeader(true)
+	<-worker.started
+
+	// callbacks return while the runnable is stopping
+	elector.setLeader(false)
+	<-worker.stopped
+	elector.setLeader(true)
+	assert.Len(t, worker.started, 0)
+
+	// it runs again once the previous run is stopped
+	close(worker.release)
+	<-worker.started
+
+	<-r.Stop()
+}
+
+func TestLeaderOnlyRunnableWithoutElector(t *testing.T) {
+	sv := newTestService(
+		WithInitRunnable(&testComponent{prefix: "leader"}),
+		WithLeaderOnlyRunnable("leader", newWorkerRunnable()),
+	)
+
+	assert.ErrorIs(t, sv.subServices[0].Run(), ErrComponentType)
+}
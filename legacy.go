@@ -0,0 +1,79 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"flag"
+	"sync"
+)
+
+// LegacyRunnable is a Runnable registering its flags on the global flag.CommandLine,
+// as components did before InitFlags received the flag set of the service
+type LegacyRunnable interface {
+	Name() string
+	InitFlags()
+	Configure() error
+	Run() error
+	Stop() <-chan bool
+}
+
+// LegacyPrefixRunnable is a PrefixRunnable registering its flags on the global flag.CommandLine
+type LegacyPrefixRunnable interface {
+	HasPrefix
+	LegacyRunnable
+}
+
+// FromLegacy adapts r to Runnable: its flags are registered on the flag set of the service.
+// Ex: goservice.WithRunnable(goservice.FromLegacy(myComponent))
+func FromLegacy(r LegacyRunnable) Runnable {
+	return &legacyRunnable{r}
+}
+
+// FromLegacyPrefix adapts r to PrefixRunnable: its flags are registered on the flag set of the service.
+// Ex: goservice.WithInitRunnable(goservice.FromLegacyPrefix(myComponent))
+func FromLegacyPrefix(r LegacyPrefixRunnable) PrefixRunnable {
+	return &legacyPrefixRunnable{r}
+}
+
+type legacyRunnable struct {
+	LegacyRunnable
+}
+
+func (r *legacyRunnable) InitFlags(fs *flag.FlagSet) {
+	withCommandLine(fs, r.LegacyRunnable.InitFlags)
+}
+
+func (r *legacyRunnable) unwrap() interface{} { return r.LegacyRunnable }
+
+type legacyPrefixRunnable struct {
+	LegacyPrefixRunnable
+}
+
+func (r *legacyPrefixRunnable) InitFlags(fs *flag.FlagSet) {
+	withCommandLine(fs, r.LegacyPrefixRunnable.InitFlags)
+}
+
+func (r *legacyPrefixRunnable) unwrap() interface{} { return r.LegacyPrefixRunnable }
+
+var commandLineMu sync.Mutex
+
+// withCommandLine runs fn with fs as the global flag.CommandLine
+func withCommandLine(fs *flag.FlagSet, fn func()) {
+	commandLineMu.Lock()
+	defer commandLineMu.Unlock()
+
+	commandLine := flag.CommandLine
+	flag.CommandLine = fs
+	defer func() { flag.CommandLine = commandLine }()
+
+	fn()
+}
+
+// unwrapComponent returns the component adapted by FromLegacy or FromLegacyPrefix,
+// optional interfaces (Ex: HealthChecker) must be checked on it
+func unwrapComponent(c interface{}) interface{} {
+	if w, ok := c.(interface{ unwrap() interface{} }); ok {
+		return w.unwrap()
+	}
+	return c
+}
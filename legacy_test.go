@@ -0,0 +1,29 @@
+package goservice
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type legacyComponent struct {
+	testComponent
+	value string
+}
+
+func (c *legacyComponent) InitFlags() {
+	flag.StringVar(&c.value, c.prefix+"-value", "", "value of legacy component")
+}
+
+func TestServicesWithLegacyComponent(t *testing.T) {
+	first, second := &legacyComponent{testComponent: testComponent{prefix: "legacy"}},
+		&legacyComponent{testComponent: testComponent{prefix: "legacy"}}
+
+	New(WithName("first"), WithInitRunnable(FromLegacyPrefix(first)), WithArgs("-legacy-value=first"))
+	New(WithName("second"), WithInitRunnable(FromLegacyPrefix(second)), WithArgs("-legacy-value=second"))
+
+	assert.Equal(t, "first", first.value)
+	assert.Equal(t, "second", second.value)
+	assert.Nil(t, flag.CommandLine.Lookup("legacy-value"))
+}
@@ -0,0 +1,47 @@
+package logger
+
+import "context"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	loggerKey
+)
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the request, see FromContext
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the ID of the request carried by ctx, empty if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// NewContext returns a copy of ctx carrying l, which is returned by FromContext
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger carried by ctx, or the logger of the service,
+// with the ID of the request in every log line:
+//
+//	logger.FromContext(c.Request.Context()).Errorln("cannot create order:", err)
+func FromContext(ctx context.Context) Logger {
+	l, ok := ctx.Value(loggerKey).(Logger)
+	if !ok {
+		sl := GetCurrent()
+		if sl == nil {
+			sl = DefaultStdLogger
+		}
+		l = sl.GetLogger("")
+	}
+
+	if id := RequestIDFromContext(ctx); id != "" {
+		return l.With("request_id", id)
+	}
+
+	return l
+}
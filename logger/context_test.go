@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	s := NewAppLogService(&Config{BasePrefix: "core", DefaultLevel: "info"})
+	require.NoError(t, s.Configure())
+
+	out := new(bytes.Buffer)
+	s.logger.SetOutput(out)
+
+	ctx := NewContext(context.Background(), s.GetLogger("orders"))
+	ctx = ContextWithRequestID(ctx, "req-1")
+
+	assert.Equal(t, "req-1", RequestIDFromContext(ctx))
+	FromContext(ctx).Infoln("order created")
+
+	assert.Contains(t, out.String(), "request_id=req-1")
+	assert.Contains(t, out.String(), "core.orders")
+	assert.Contains(t, out.String(), "order created")
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+	assert.NotNil(t, FromContext(context.Background()))
+}
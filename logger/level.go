@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelController is implemented by ServiceLogger to change log levels at runtime
+type LevelController interface {
+	// SetLevel sets the level of loggers with prefix and its sub prefixes.
+	// Ex: "gin", "io.socket". An empty prefix sets the default level,
+	// an empty level removes the level of prefix so it has the default one
+	SetLevel(prefix, level string) error
+	// Levels returns the default level with key "" and levels set by prefix
+	Levels() map[string]string
+}
+
+// levels of loggers by prefix, the default one is used by prefixes without level.
+// The logrus logger is set to the most verbose of them, each logger filters
+// entries by the level of its prefix
+type levels struct {
+	mu       sync.RWMutex
+	logger   *logrus.Logger
+	global   logrus.Level
+	prefixes map[string]logrus.Level
+}
+
+func newLevels(l *logrus.Logger) *levels {
+	return &levels{logger: l, global: l.GetLevel(), prefixes: map[string]logrus.Level{}}
+}
+
+// of returns the level of the longest prefix matching prefix
+func (lv *levels) of(prefix string) logrus.Level {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	for p := prefix; p != ""; {
+		if level, ok := lv.prefixes[p]; ok {
+			return level
+		}
+
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+
+	return lv.global
+}
+
+func (lv *levels) setGlobal(level logrus.Level) {
+	lv.mu.Lock()
+	lv.global = level
+	lv.apply()
+	lv.mu.Unlock()
+}
+
+func (lv *levels) set(prefix, level string) error {
+	if prefix == "" {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		lv.setGlobal(parsed)
+		return nil
+	}
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if level == "" {
+		delete(lv.prefixes, prefix)
+	} else {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		lv.prefixes[prefix] = parsed
+	}
+
+	lv.apply()
+	return nil
+}
+
+func (lv *levels) all() map[string]string {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+
+	m := map[string]string{"": lv.global.String()}
+	for p, level := range lv.prefixes {
+		m[p] = level.String()
+	}
+	return m
+}
+
+// apply sets the logrus logger to the most verbose level, the lock must be held
+func (lv *levels) apply() {
+	max := lv.global
+	for _, level := range lv.prefixes {
+		if level > max {
+			max = level
+		}
+	}
+	lv.logger.SetLevel(max)
+}
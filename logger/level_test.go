@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixLevels(t *testing.T) {
+	s := NewAppLogService(&Config{BasePrefix: "core", DefaultLevel: "info"})
+	require.NoError(t, s.Configure())
+
+	out := new(bytes.Buffer)
+	s.logger.SetOutput(out)
+
+	gin, socket, service := s.GetLogger("gin"), s.GetLogger("io.socket"), s.GetLogger("service")
+
+	require.NoError(t, s.SetLevel("gin", "debug"))
+	require.NoError(t, s.SetLevel("io", "error"))
+	assert.Error(t, s.SetLevel("gin", "verbose"))
+
+	assert.Equal(t, "debug", gin.GetLevel())
+	assert.Equal(t, "error", socket.GetLevel(), "sub prefixes have the level of their parent")
+	assert.Equal(t, "info", service.GetLevel())
+	assert.Equal(t, map[string]string{"": "info", "gin": "debug", "io": "error"}, s.Levels())
+
+	gin.With("request", 1).Debugln("gin debug")
+	service.Debugln("service debug")
+	socket.Warnln("socket warn")
+	service.Infoln("service info")
+
+	assert.Contains(t, out.String(), "gin debug")
+	assert.Contains(t, out.String(), "service info")
+	assert.NotContains(t, out.String(), "service debug")
+	assert.NotContains(t, out.String(), "socket warn")
+
+	// back to the default level
+	require.NoError(t, s.SetLevel("gin", ""))
+	require.NoError(t, s.SetLevel("", "warn"))
+	assert.Equal(t, "warning", gin.GetLevel())
+	assert.Equal(t, "warning", s.logger.GetLevel().String(), "logrus has the most verbose level")
+}
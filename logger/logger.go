@@ -70,10 +70,24 @@ type Logger interface {
 
 type logger struct {
 	*logrus.Entry
+	// levels by prefix, nil to use the level of the logrus logger
+	levels *levels
+	prefix string
+}
+
+func (l *logger) level() logrus.Level {
+	if l.levels == nil {
+		return l.Entry.Logger.GetLevel()
+	}
+	return l.levels.of(l.prefix)
+}
+
+func (l *logger) enabled(level logrus.Level) bool {
+	return l.level() >= level
 }
 
 func (l *logger) GetLevel() string {
-	return l.Entry.Logger.Level.String()
+	return l.level().String()
 }
 
 func (l *logger) debugSrc() *logrus.Entry {
@@ -94,39 +108,93 @@ func (l *logger) debugSrc() *logrus.Entry {
 }
 
 func (l *logger) Debug(args ...interface{}) {
-	if l.Entry.Logger.Level >= logrus.DebugLevel {
+	if l.enabled(logrus.DebugLevel) {
 		l.debugSrc().Debug(args...)
 	}
 }
 
 func (l *logger) Debugln(args ...interface{}) {
-	if l.Entry.Logger.Level >= logrus.DebugLevel {
+	if l.enabled(logrus.DebugLevel) {
 		l.debugSrc().Debugln(args...)
 	}
 }
 
 func (l *logger) Debugf(format string, args ...interface{}) {
-	if l.Entry.Logger.Level >= logrus.DebugLevel {
+	if l.enabled(logrus.DebugLevel) {
 		l.debugSrc().Debugf(format, args...)
 	}
 }
 
 func (l *logger) Print(args ...interface{}) {
-	if l.Entry.Logger.Level >= logrus.DebugLevel {
-		l.debugSrc().Debug(args)
+	if l.enabled(logrus.DebugLevel) {
+		l.debugSrc().Debug(args...)
+	}
+}
+
+func (l *logger) Info(args ...interface{}) {
+	if l.enabled(logrus.InfoLevel) {
+		l.Entry.Info(args...)
+	}
+}
+
+func (l *logger) Infoln(args ...interface{}) {
+	if l.enabled(logrus.InfoLevel) {
+		l.Entry.Infoln(args...)
+	}
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	if l.enabled(logrus.InfoLevel) {
+		l.Entry.Infof(format, args...)
+	}
+}
+
+func (l *logger) Warn(args ...interface{}) {
+	if l.enabled(logrus.WarnLevel) {
+		l.Entry.Warn(args...)
+	}
+}
+
+func (l *logger) Warnln(args ...interface{}) {
+	if l.enabled(logrus.WarnLevel) {
+		l.Entry.Warnln(args...)
+	}
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	if l.enabled(logrus.WarnLevel) {
+		l.Entry.Warnf(format, args...)
+	}
+}
+
+func (l *logger) Error(args ...interface{}) {
+	if l.enabled(logrus.ErrorLevel) {
+		l.Entry.Error(args...)
+	}
+}
+
+func (l *logger) Errorln(args ...interface{}) {
+	if l.enabled(logrus.ErrorLevel) {
+		l.Entry.Errorln(args...)
+	}
+}
+
+func (l *logger) Errorf(format string, args ...interface{}) {
+	if l.enabled(logrus.ErrorLevel) {
+		l.Entry.Errorf(format, args...)
 	}
 }
 
 func (l *logger) With(key string, value interface{}) Logger {
-	return &logger{l.Entry.WithField(key, value)}
+	return &logger{Entry: l.Entry.WithField(key, value), levels: l.levels, prefix: l.prefix}
 }
 
 func (l *logger) Withs(fields Fields) Logger {
-	return &logger{l.Entry.WithFields(logrus.Fields(fields))}
+	return &logger{Entry: l.Entry.WithFields(logrus.Fields(fields)), levels: l.levels, prefix: l.prefix}
 }
 
 func (l *logger) WithSrc() Logger {
-	return &logger{l.debugSrc()}
+	return &logger{Entry: l.debugSrc(), levels: l.levels, prefix: l.prefix}
 }
 
 func mustParseLevel(level string) logrus.Level {
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"github.com/evalphobia/logrus_sentry"
 	"github.com/sirupsen/logrus"
+	"os"
 	"time"
 )
 
@@ -60,7 +61,7 @@ func NewMessageLogService(config *Config) *messageLogger {
 		}
 	}
 
-	log := &logger{logrus.NewEntry(newLog)}
+	log := &logger{Entry: logrus.NewEntry(newLog)}
 
 	return &messageLogger{
 		stdLogger: appLog,
@@ -71,16 +72,16 @@ func NewMessageLogService(config *Config) *messageLogger {
 // Implement Runnable interface
 func (m *messageLogger) Name() string { return "file-logger" }
 
-func (m *messageLogger) InitFlags() {
-	flag.StringVar(&m.logPath, "logfile", "", "file to write log to. Default write to console")
-	m.stdLogger.InitFlags()
+func (m *messageLogger) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&m.logPath, "logfile", "", "file to write log to. Default write to console")
+	m.stdLogger.InitFlags(fs)
 }
 
 func (m *messageLogger) Configure() error {
 
 	if m.logPath == "" {
 		lv := mustParseLevel(m.stdLogger.cfg.DefaultLevel)
-		m.stdLogger.logger.SetLevel(lv)
+		m.stdLogger.levels.setGlobal(lv)
 		return nil
 	}
 
@@ -93,6 +94,48 @@ func (m *messageLogger) Configure() error {
 	return nil
 }
 
+// Reconfigure applies the log level and log file of fs, it reopens the log file,
+// so it works with log rotation tools sending SIGHUP
+func (m *messageLogger) Reconfigure(fs *flag.FlagSet) error {
+	if err := m.stdLogger.Reconfigure(fs); err != nil {
+		return err
+	}
+
+	current, isFile := m.logger.Out.(*reloadFile)
+
+	logPath := ""
+	if isFile {
+		logPath = current.fname
+	}
+	if f := fs.Lookup("logfile"); f != nil {
+		logPath = f.Value.String()
+	}
+
+	if logPath == "" {
+		if isFile {
+			m.logger.SetOutput(os.Stderr)
+			current.Close()
+		}
+		return nil
+	}
+
+	if isFile && current.fname == logPath {
+		return current.ReOpen()
+	}
+
+	out, err := newReloadFile(logPath)
+	if err != nil {
+		return err
+	}
+
+	m.logger.SetOutput(out)
+	if isFile {
+		current.Close()
+	}
+
+	return nil
+}
+
 func (m *messageLogger) Run() error {
 	return m.Configure()
 }
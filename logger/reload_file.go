@@ -27,12 +27,16 @@ func newReloadFile(path string) (*reloadFile, error) {
 
 func (r *reloadFile) ReOpen() error {
 	f, err := os.OpenFile(r.fname, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		// keep writing to the old file
+		return err
+	}
 
 	pOld := atomic.SwapPointer(&r.pFile, unsafe.Pointer(f))
 	f = (*os.File)(pOld)
 	_ = f.Close()
 
-	return err
+	return nil
 }
 
 func (r *reloadFile) Write(p []byte) (int, error) {
@@ -42,6 +42,7 @@ type stdLogger struct {
 	logger   *logrus.Logger
 	cfg      Config
 	logLevel string
+	levels   *levels
 }
 
 func NewAppLogService(config *Config) *stdLogger {
@@ -79,21 +80,21 @@ func NewAppLogService(config *Config) *stdLogger {
 		logger:   logger,
 		cfg:      *config,
 		logLevel: config.DefaultLevel,
+		levels:   newLevels(logger),
 	}
 }
 
 func (s *stdLogger) GetLogger(prefix string) Logger {
 	var entry *logrus.Entry
 
-	prefix = s.cfg.BasePrefix + "." + prefix
-	prefix = strings.Trim(prefix, ".")
-	if prefix == "" {
+	fullPrefix := strings.Trim(s.cfg.BasePrefix+"."+prefix, ".")
+	if fullPrefix == "" {
 		entry = logrus.NewEntry(s.logger)
 	} else {
-		entry = s.logger.WithField("prefix", prefix)
+		entry = s.logger.WithField("prefix", fullPrefix)
 	}
 
-	l := &logger{entry}
+	l := &logger{Entry: entry, levels: s.levels, prefix: prefix}
 	var log Logger = l
 
 	return log
@@ -101,16 +102,45 @@ func (s *stdLogger) GetLogger(prefix string) Logger {
 
 // Implement Runnable interface
 func (s *stdLogger) Name() string { return "file-logger" }
-func (s *stdLogger) InitFlags() {
+func (s *stdLogger) InitFlags(fs *flag.FlagSet) {
 	if os.Getenv("LOG_LEVEL") != "" {
 		s.logLevel = os.Getenv("LOG_LEVEL")
 	} else {
-		flag.StringVar(&s.logLevel, "log-level", s.cfg.DefaultLevel, "Log level: panic | fatal | error | warn | info | debug | trace")
+		fs.StringVar(&s.logLevel, "log-level", s.cfg.DefaultLevel, "Log level: panic | fatal | error | warn | info | debug | trace")
 	}
 }
 func (s *stdLogger) Configure() error {
 	lv := mustParseLevel(s.logLevel)
-	s.logger.SetLevel(lv)
+	s.levels.setGlobal(lv)
+	return nil
+}
+
+// SetLevel sets the level of loggers with prefix at runtime, see LevelController
+func (s *stdLogger) SetLevel(prefix, level string) error {
+	return s.levels.set(prefix, level)
+}
+
+// Levels returns the default level and levels set by prefix
+func (s *stdLogger) Levels() map[string]string {
+	return s.levels.all()
+}
+
+// Reconfigure applies the log level of fs, it keeps the current one if the new level is invalid
+func (s *stdLogger) Reconfigure(fs *flag.FlagSet) error {
+	level := os.Getenv("LOG_LEVEL")
+	if f := fs.Lookup("log-level"); level == "" && f != nil {
+		level = f.Value.String()
+	}
+	if level == "" {
+		return nil
+	}
+
+	lv, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	s.levels.setGlobal(lv)
 	return nil
 }
 
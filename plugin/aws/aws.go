@@ -7,6 +7,8 @@
 package aws
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,6 +17,7 @@ import (
 	s32 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/lequocbinh04/go-sdk/logger"
 	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 )
 
 var (
@@ -64,12 +67,13 @@ func (s *s3) Name() string {
 	return s.name
 }
 
-func (s *s3) InitFlags() {
-	flag.StringVar(&s.cfg.s3ApiKey, fmt.Sprintf("%s-%s", s.GetPrefix(), "api-key"), "", "S3 API key")
-	flag.StringVar(&s.cfg.s3ApiSecret, fmt.Sprintf("%s-%s", s.GetPrefix(), "api-secret"), "", "S3 API secret key")
-	flag.StringVar(&s.cfg.s3Region, fmt.Sprintf("%s-%s", s.GetPrefix(), "region"), "", "S3 region")
-	flag.StringVar(&s.cfg.s3Bucket, fmt.Sprintf("%s-%s", s.GetPrefix(), "bucket"), "", "S3 bucket")
-	flag.StringVar(&s.cfg.s3Endpoint, fmt.Sprintf("%s-%s", s.GetPrefix(), "endpoint"), "", "S3 endpoint")
+func (s *s3) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.cfg.s3ApiKey, fmt.Sprintf("%s-%s", s.GetPrefix(), "api-key"), "", "S3 API key")
+	fs.StringVar(&s.cfg.s3ApiSecret, fmt.Sprintf("%s-%s", s.GetPrefix(), "api-secret"), "", "S3 API secret key")
+	fs.StringVar(&s.cfg.s3Region, fmt.Sprintf("%s-%s", s.GetPrefix(), "region"), "", "S3 region")
+	fs.StringVar(&s.cfg.s3Bucket, fmt.Sprintf("%s-%s", s.GetPrefix(), "bucket"), "", "S3 bucket")
+	fs.StringVar(&s.cfg.s3Endpoint, fmt.Sprintf("%s-%s", s.GetPrefix(), "endpoint"), "", "S3 endpoint")
+	secret.MarkFlag(fmt.Sprintf("%s-%s", s.GetPrefix(), "api-key"), fmt.Sprintf("%s-%s", s.GetPrefix(), "api-secret"))
 }
 
 func (s *s3) Configure() error {
@@ -114,6 +118,18 @@ func (s *s3) Stop() <-chan bool {
 	return c
 }
 
+func (s *s3) Health(ctx context.Context) error {
+	if s.service == nil {
+		return errors.New("aws s3 is not configured")
+	}
+
+	_, err := s.service.HeadBucketWithContext(ctx, &s32.HeadBucketInput{
+		Bucket: aws.String(s.cfg.s3Bucket),
+	})
+
+	return err
+}
+
 func (cfg *s3Config) check() error {
 	if len(cfg.s3ApiKey) < 1 {
 		return ErrS3ApiKeyMissing
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/lequocbinh04/go-sdk/logger"
 	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 )
 
 var (
@@ -53,10 +54,11 @@ func (cd *cloudinary) Name() string {
 	return cd.name
 }
 
-func (cd *cloudinary) InitFlags() {
-	flag.StringVar(&cd.config.apiKey, fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-key"), "", "Cloudinary api key")
-	flag.StringVar(&cd.config.apiSecret, fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-secret"), "", "Cloudinary api secret")
-	flag.StringVar(&cd.config.cloudName, fmt.Sprintf("%s-%s", cd.GetPrefix(), "cloud-name"), "", "Cloudinary cloud name")
+func (cd *cloudinary) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cd.config.apiKey, fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-key"), "", "Cloudinary api key")
+	fs.StringVar(&cd.config.apiSecret, fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-secret"), "", "Cloudinary api secret")
+	fs.StringVar(&cd.config.cloudName, fmt.Sprintf("%s-%s", cd.GetPrefix(), "cloud-name"), "", "Cloudinary cloud name")
+	secret.MarkFlag(fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-key"), fmt.Sprintf("%s-%s", cd.GetPrefix(), "api-secret"))
 }
 
 func (cd *cloudinary) Configure() error {
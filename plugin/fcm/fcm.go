@@ -12,6 +12,7 @@ import (
 	"fmt"
 	gofcm "github.com/NaySoftware/go-fcm"
 	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 )
 
 type FirebaseCloudMessaging interface {
@@ -65,8 +66,9 @@ func (s *fcmClient) Name() string {
 	return s.name
 }
 
-func (s *fcmClient) InitFlags() {
-	flag.StringVar(&s.apiKey, fmt.Sprintf("%s-api-key", s.Name()), "", "firebase cloud messaging api key")
+func (s *fcmClient) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.apiKey, fmt.Sprintf("%s-api-key", s.Name()), "", "firebase cloud messaging api key")
+	secret.MarkFlag(fmt.Sprintf("%s-api-key", s.Name()))
 }
 
 func (s *fcmClient) Configure() error {
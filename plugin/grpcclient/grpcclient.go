@@ -0,0 +1,270 @@
+// Package grpcclient manages named gRPC client connections configured by flags:
+//
+//	clients := grpcclient.New("grpc-client", "user", "order")
+//	service := goservice.New(goservice.WithInitRunnable(clients))
+//	...
+//	conn, err := clients.Conn("user") // flags: -grpc-client-user-target, -grpc-client-user-tls...
+//	userClient := pb.NewUserClient(conn)
+//
+// Calls are traced with OpenTelemetry (see plugin/tracing), get a default timeout, calls of
+// idempotent methods set by -retry-methods are retried with backoff when the server is unavailable,
+// and status carrying an *sdkcm.AppError are decoded back to it, see util/grpcerr.
+package grpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var ErrClientNotFound = errors.New("grpc client not found")
+
+type clientConfig struct {
+	target        string
+	tls           bool
+	tlsCAFile     string
+	tlsCertFile   string
+	tlsKeyFile    string
+	tlsServerName string
+	timeout       time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+	retryMethods  string
+}
+
+type client struct {
+	name string
+	cfg  clientConfig
+	conn *grpc.ClientConn
+}
+
+type grpcClients struct {
+	prefix  string
+	logger  logger.Logger
+	clients []*client
+	options []grpc.DialOption
+	mu      sync.RWMutex
+}
+
+// New creates the component with a connection for each name
+func New(prefix string, names ...string) *grpcClients {
+	gc := &grpcClients{prefix: prefix}
+	for _, name := range names {
+		gc.clients = append(gc.clients, &client{name: name})
+	}
+	return gc
+}
+
+// AddDialOption adds options used to dial every connection. Ex: grpc.WithUserAgent
+func (gc *grpcClients) AddDialOption(opts ...grpc.DialOption) {
+	gc.options = append(gc.options, opts...)
+}
+
+func (gc *grpcClients) GetPrefix() string {
+	return gc.prefix
+}
+
+func (gc *grpcClients) Get() interface{} {
+	return gc
+}
+
+func (gc *grpcClients) Name() string {
+	return gc.prefix
+}
+
+func (gc *grpcClients) InitFlags(fs *flag.FlagSet) {
+	for _, c := range gc.clients {
+		pre := fmt.Sprintf("%s-%s", gc.prefix, c.name)
+
+		fs.StringVar(&c.cfg.target, pre+"-target", "", "Target of grpc client "+c.name+". Ex: localhost:50051, dns:///user:50051")
+		fs.BoolVar(&c.cfg.tls, pre+"-tls", false, "Connect to grpc server "+c.name+" with TLS")
+		fs.StringVar(&c.cfg.tlsCAFile, pre+"-tls-ca-file", "", "CA certificate file to verify the server, system CAs if empty")
+		fs.StringVar(&c.cfg.tlsCertFile, pre+"-tls-cert-file", "", "Client certificate file for mTLS")
+		fs.StringVar(&c.cfg.tlsKeyFile, pre+"-tls-key-file", "", "Client key file for mTLS")
+		fs.StringVar(&c.cfg.tlsServerName, pre+"-tls-server-name", "", "Server name to verify, the target host if empty")
+		fs.DurationVar(&c.cfg.timeout, pre+"-timeout", 10*time.Second, "Timeout of a call including its retries, 0 to disable")
+		fs.IntVar(&c.cfg.maxRetries, pre+"-max-retries", 3, "Max retries of a call when the server is unavailable")
+		fs.DurationVar(&c.cfg.retryBackoff, pre+"-retry-backoff", 100*time.Millisecond,
+			"Backoff before the first retry, doubled for each next one")
+		fs.StringVar(&c.cfg.retryMethods, pre+"-retry-methods", "",
+			"Comma-separated idempotent methods retried when the server is unavailable, none if empty. Ex: /user.User/Get")
+	}
+}
+
+func (gc *grpcClients) Configure() error {
+	gc.logger = logger.GetCurrent().GetLogger(gc.prefix)
+
+	for _, c := range gc.clients {
+		if c.cfg.target == "" {
+			return fmt.Errorf("grpc client %s: target is empty", c.name)
+		}
+	}
+
+	return nil
+}
+
+func (gc *grpcClients) Run() error {
+	if err := gc.Configure(); err != nil {
+		return err
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	for _, c := range gc.clients {
+		opts, err := gc.dialOptions(c.cfg)
+		if err != nil {
+			return fmt.Errorf("grpc client %s: %w", c.name, err)
+		}
+
+		// connections are established in the background, calls wait for them
+		conn, err := grpc.Dial(c.cfg.target, opts...)
+		if err != nil {
+			return fmt.Errorf("grpc client %s: %w", c.name, err)
+		}
+
+		gc.logger.Infof("grpc client %s connects to %s", c.name, c.cfg.target)
+		c.conn = conn
+	}
+
+	return nil
+}
+
+func (gc *grpcClients) dialOptions(cfg clientConfig) ([]grpc.DialOption, error) {
+	creds := insecure.NewCredentials()
+
+	if cfg.tls {
+		tlsCfg, err := tlsConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(
+			otelgrpc.UnaryClientInterceptor(),
+			UnaryAppError(),
+			UnaryTimeout(cfg.timeout),
+			UnaryRetry(cfg.maxRetries, cfg.retryBackoff, splitMethods(cfg.retryMethods)...),
+		),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor(), StreamAppError()),
+	}
+
+	return append(opts, gc.options...), nil
+}
+
+func splitMethods(s string) []string {
+	var methods []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+func tlsConfig(cfg clientConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{ServerName: cfg.tlsServerName, MinVersion: tls.VersionTLS12}
+
+	if cfg.tlsCAFile != "" {
+		ca, err := os.ReadFile(cfg.tlsCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate in %s", cfg.tlsCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.tlsCertFile != "" || cfg.tlsKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.tlsCertFile, cfg.tlsKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// Conn returns the connection with name, it is shared and must not be closed
+func (gc *grpcClients) Conn(name string) (*grpc.ClientConn, error) {
+	gc.mu.RLock()
+	defer gc.mu.RUnlock()
+
+	for _, c := range gc.clients {
+		if c.name == name {
+			if c.conn == nil {
+				return nil, fmt.Errorf("grpc client %s is not running", name)
+			}
+			return c.conn, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrClientNotFound, name)
+}
+
+// MustConn returns the connection with name, it panics if there is no one
+func (gc *grpcClients) MustConn(name string) *grpc.ClientConn {
+	conn, err := gc.Conn(name)
+	if err != nil {
+		panic(err)
+	}
+	return conn
+}
+
+// Health returns an error if a connection fails to connect
+func (gc *grpcClients) Health(ctx context.Context) error {
+	gc.mu.RLock()
+	defer gc.mu.RUnlock()
+
+	for _, c := range gc.clients {
+		if c.conn == nil {
+			continue
+		}
+
+		if state := c.conn.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			return fmt.Errorf("grpc client %s: connection is %s", c.name, state)
+		}
+	}
+
+	return nil
+}
+
+func (gc *grpcClients) Stop() <-chan bool {
+	c := make(chan bool)
+
+	go func() {
+		gc.mu.Lock()
+		for _, cl := range gc.clients {
+			if cl.conn != nil {
+				_ = cl.conn.Close()
+				cl.conn = nil
+			}
+		}
+		gc.mu.Unlock()
+
+		c <- true
+	}()
+
+	return c
+}
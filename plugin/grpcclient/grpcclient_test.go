@@ -0,0 +1,171 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/grpcserver"
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/grpcerr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// userService has a method failing with an AppError, registered without generated code
+var userService = grpc.ServiceDesc{
+	ServiceName: "test.User",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Get",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(emptypb.Empty)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.User/Get"}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, sdkcm.ErrEntityNotFound("User", sdkcm.ErrRecordNotFound)
+			})
+		},
+	}},
+}
+
+func startServer(t *testing.T) int {
+	gs := grpcserver.New("test")
+	gs.Config = grpcserver.Config{BindAddr: "127.0.0.1"}
+	gs.AddHandler(func(s *grpc.Server) { s.RegisterService(&userService, struct{}{}) })
+
+	go func() { _ = gs.Run() }()
+	t.Cleanup(func() { <-gs.Stop() })
+
+	return gs.Port()
+}
+
+func newTestClients(target string) *grpcClients {
+	gc := New("grpc-client", "user")
+	gc.clients[0].cfg = clientConfig{target: target, timeout: 5 * time.Second, maxRetries: 2, retryBackoff: 10 * time.Millisecond}
+	return gc
+}
+
+func TestClient(t *testing.T) {
+	logger.InitServLogger(false)
+
+	gc := newTestClients(fmt.Sprintf("127.0.0.1:%d", startServer(t)))
+	require.NoError(t, gc.Run())
+	defer func() { <-gc.Stop() }()
+
+	conn, err := gc.Conn("user")
+	require.NoError(t, err)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.NoError(t, gc.Health(context.Background()))
+
+	err = conn.Invoke(context.Background(), "/test.User/Get", &emptypb.Empty{}, &emptypb.Empty{})
+
+	var appErr *sdkcm.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, "ErrUserNotFound", appErr.Key)
+	assert.Equal(t, "user not found", appErr.Message)
+
+	_, err = gc.Conn("order")
+	assert.ErrorIs(t, err, ErrClientNotFound)
+}
+
+func TestTracing(t *testing.T) {
+	logger.InitServLogger(false)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	gc := newTestClients(fmt.Sprintf("127.0.0.1:%d", startServer(t)))
+	require.NoError(t, gc.Run())
+	defer func() { <-gc.Stop() }()
+
+	conn, err := gc.Conn("user")
+	require.NoError(t, err)
+	_ = conn.Invoke(context.Background(), "/test.User/Get", &emptypb.Empty{}, &emptypb.Empty{})
+
+	var client, server sdktrace.ReadOnlySpan
+	require.Eventually(t, func() bool {
+		for _, span := range recorder.Ended() {
+			switch span.SpanKind() {
+			case trace.SpanKindClient:
+				client = span
+			case trace.SpanKindServer:
+				server = span
+			}
+		}
+		return client != nil && server != nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "test.User/Get", server.Name())
+	assert.Equal(t, client.SpanContext().TraceID(), server.SpanContext().TraceID())
+	assert.Equal(t, client.SpanContext().SpanID(), server.Parent().SpanID())
+}
+
+func TestConfigure(t *testing.T) {
+	logger.InitServLogger(false)
+	assert.Error(t, newTestClients("").Configure())
+}
+
+func TestUnaryRetry(t *testing.T) {
+	var calls int
+	invoker := func(err error) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			return err
+		}
+	}
+
+	unavailable := status.Error(codes.Unavailable, "failed")
+	retry := UnaryRetry(2, time.Millisecond, "/test.User/Get")
+
+	err := retry(context.Background(), "/test.User/Get", nil, nil, nil, invoker(unavailable))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retry(context.Background(), "/test.User/Create", nil, nil, nil, invoker(unavailable))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls, "only listed methods are retried")
+
+	calls = 0
+	err = retry(context.Background(), "/test.User/Get", nil, nil, nil, invoker(status.Error(codes.InvalidArgument, "failed")))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 1, calls, "only unavailable calls are retried")
+
+	calls = 0
+	appErr := grpcerr.ToError(sdkcm.NewCustomError(http.StatusServiceUnavailable, errors.New("maintenance"), "maintenance", "ErrMaintenance"))
+	err = retry(context.Background(), "/test.User/Get", nil, nil, nil, invoker(appErr))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls, "AppError returned by the server is not retried")
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = UnaryRetry(5, time.Hour, "/test.User/Get")(ctx, "/test.User/Get", nil, nil, nil, invoker(unavailable))
+	assert.Equal(t, 1, calls, "retries stop when the context is done")
+}
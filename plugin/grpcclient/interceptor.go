@@ -0,0 +1,87 @@
+package grpcclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/lequocbinh04/go-sdk/util/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAppError decodes status carrying an *sdkcm.AppError back to it
+func UnaryAppError() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return grpcerr.FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamAppError decodes the status of opening a stream back to *sdkcm.AppError
+func StreamAppError() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		return stream, grpcerr.FromError(err)
+	}
+}
+
+// UnaryTimeout sets a deadline to calls without one
+func UnaryTimeout(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryRetry retries calls of methods failing with Unavailable or ResourceExhausted up to maxRetries times.
+// Retries are opt-in: only the listed methods (Ex: /user.User/Get) are retried, they must be idempotent.
+// Errors returned by handlers of the server as *sdkcm.AppError are never retried.
+// It waits backoff before the first retry, then doubles it, until the context is done
+func UnaryRetry(maxRetries int, backoff time.Duration, methods ...string) grpc.UnaryClientInterceptor {
+	retried := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		retried[m] = true
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if !retried[method] {
+			return err
+		}
+
+		for attempt := 0; attempt < maxRetries && isRetryable(err); attempt++ {
+			timer := time.NewTimer(backoff << attempt)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		return err
+	}
+}
+
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		// the handler of the server failed with an AppError, retrying would not change it
+		_, isAppErr := grpcerr.FromError(err).(*sdkcm.AppError)
+		return !isAppErr
+	}
+	return false
+}
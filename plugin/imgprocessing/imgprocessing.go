@@ -43,8 +43,8 @@ func (imgproc *imgProcessing) Name() string {
 	return imgproc.name
 }
 
-func (imgproc *imgProcessing) InitFlags() {
-	flag.StringVar(&imgproc.cfg.host, fmt.Sprintf("%s-%s", imgproc.GetPrefix(), "host"), "", "img processing host")
+func (imgproc *imgProcessing) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&imgproc.cfg.host, fmt.Sprintf("%s-%s", imgproc.GetPrefix(), "host"), "", "img processing host")
 }
 
 func (imgproc *imgProcessing) GetPrefix() string {
@@ -26,6 +26,10 @@ type jaeger struct {
 	stdTracingEnabled bool
 }
 
+// NewJaeger exports opencensus spans to a Jaeger agent.
+//
+// Deprecated: the opencensus Jaeger exporter is no longer maintained, use plugin/tracing
+// which exports OpenTelemetry spans with OTLP
 func NewJaeger(processName string) *jaeger {
 	return &jaeger{
 		processName: processName,
@@ -46,29 +50,29 @@ func (j *jaeger) Get() interface{} {
 	return nil
 }
 
-func (j *jaeger) InitFlags() {
-	flag.Float64Var(
+func (j *jaeger) InitFlags(fs *flag.FlagSet) {
+	fs.Float64Var(
 		&j.sampleTraceRating,
 		"jaeger-trace-sample-rate",
 		1.0,
 		"sample rating for remote tracing from OpenSensus: 0.0 -> 1.0 (default is 1.0)",
 	)
 
-	flag.StringVar(
+	fs.StringVar(
 		&j.agentURI,
 		"jaeger-agent-uri",
 		"",
 		"jaeger agent URI to receive tracing data directly",
 	)
 
-	flag.IntVar(
+	fs.IntVar(
 		&j.port,
 		"jaeger-agent-port",
 		6831,
 		"jaeger agent URI to receive tracing data directly",
 	)
 
-	flag.BoolVar(
+	fs.BoolVar(
 		&j.stdTracingEnabled,
 		"jaeger-std-enabled",
 		false,
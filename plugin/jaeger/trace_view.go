@@ -22,6 +22,8 @@ var reZero = regexp.MustCompile(`^0+$`)
 // capabilities of opencensus.
 //
 // This should NOT be used for production workloads.
+//
+// Deprecated: use the stdout exporter of plugin/tracing
 type PrintExporter struct{}
 
 // ExportView logs the view data.
@@ -0,0 +1,268 @@
+// Package leader elects one instance of a service as leader with a lease,
+// so singleton background workers run on one replica only.
+//
+//	rdb := sdkredis.NewRedisDB("redis", "")
+//	service := goservice.New(
+//		goservice.WithInitRunnable(rdb),
+//		goservice.WithInitRunnable(leader.New("leader", leader.RedisLease(rdb))),
+//		goservice.WithLeaderOnlyRunnable("leader", worker),
+//	)
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+)
+
+const (
+	defaultTTL           = 15 * time.Second
+	defaultRenewInterval = 5 * time.Second
+)
+
+// Lease is held by one holder at a time until it expires
+type Lease interface {
+	// Acquire gets the lease of key for holder or renews it if holder has it,
+	// it returns true if holder has the lease for ttl
+	Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease of key if holder has it
+	Release(ctx context.Context, key, holder string) error
+}
+
+// Component is an init component holding data, it is implemented by
+// storage components. Ex: sdkredis, sdkgorm
+type Component interface {
+	GetPrefix() string
+	Get() interface{}
+}
+
+type leaderConfig struct {
+	key           string
+	ttl           time.Duration
+	renewInterval time.Duration
+}
+
+type leader struct {
+	prefix string
+	id     string
+	lease  Lease
+	logger logger.Logger
+	cfg    leaderConfig
+
+	mu        sync.Mutex
+	isLeader  bool
+	onElected []func()
+	onRevoked []func()
+
+	// set by Run, stopChan is closed by the first Stop
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// New creates a leader election component, instances of the service
+// campaign for the lease of the same key
+func New(prefix string, lease Lease) *leader {
+	return &leader{
+		prefix: prefix,
+		id:     newHolderID(),
+		lease:  lease,
+	}
+}
+
+func newHolderID() string {
+	hostname, _ := os.Hostname()
+
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b))
+}
+
+func (l *leader) GetPrefix() string {
+	return l.prefix
+}
+
+func (l *leader) Get() interface{} {
+	return l
+}
+
+func (l *leader) Name() string {
+	return l.prefix
+}
+
+// ID of this instance as lease holder
+func (l *leader) ID() string {
+	return l.id
+}
+
+func (l *leader) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&l.cfg.key, fmt.Sprintf("%s-%s", l.prefix, "key"), l.prefix, "Key of the leader lease, shared by all instances")
+	fs.DurationVar(&l.cfg.ttl, fmt.Sprintf("%s-%s", l.prefix, "ttl"), defaultTTL,
+		"Duration of the leader lease, another instance takes over after it if the leader is gone")
+	fs.DurationVar(&l.cfg.renewInterval, fmt.Sprintf("%s-%s", l.prefix, "renew-interval"), defaultRenewInterval,
+		"Interval to renew the leader lease or to campaign for it, it must be less than the ttl")
+}
+
+// DependsOn returns the component of the lease, so it starts before
+func (l *leader) DependsOn() []string {
+	if d, ok := l.lease.(interface{ DependsOn() []string }); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+func (l *leader) Configure() error {
+	l.logger = logger.GetCurrent().GetLogger(l.prefix)
+
+	if l.cfg.renewInterval <= 0 || l.cfg.renewInterval >= l.cfg.ttl {
+		return fmt.Errorf("leader renew interval (%s) must be positive and less than ttl (%s)",
+			l.cfg.renewInterval, l.cfg.ttl)
+	}
+
+	return nil
+}
+
+func (l *leader) Run() error {
+	if err := l.Configure(); err != nil {
+		return err
+	}
+
+	stopChan, doneChan := make(chan struct{}), make(chan struct{})
+
+	l.mu.Lock()
+	l.stopChan, l.doneChan = stopChan, doneChan
+	l.mu.Unlock()
+
+	go l.campaign(stopChan, doneChan)
+
+	return nil
+}
+
+// Stop releases the lease and waits until the election loop returns,
+// it can be called more than once
+func (l *leader) Stop() <-chan bool {
+	l.mu.Lock()
+	stopChan, doneChan := l.stopChan, l.doneChan
+	l.stopChan = nil
+	l.mu.Unlock()
+
+	if stopChan != nil {
+		close(stopChan)
+	}
+
+	c := make(chan bool)
+	go func() {
+		if doneChan != nil {
+			<-doneChan
+		}
+		c <- true
+	}()
+
+	return c
+}
+
+// IsLeader returns true while this instance has the lease
+func (l *leader) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.isLeader
+}
+
+// OnElected adds a callback run when this instance becomes leader.
+// Callbacks run one by one in the election loop, they must not block
+func (l *leader) OnElected(fn func()) {
+	l.mu.Lock()
+	l.onElected = append(l.onElected, fn)
+	l.mu.Unlock()
+}
+
+// OnRevoked adds a callback run when this instance loses the lease or stops.
+// Callbacks run one by one in the election loop, they must not block
+func (l *leader) OnRevoked(fn func()) {
+	l.mu.Lock()
+	l.onRevoked = append(l.onRevoked, fn)
+	l.mu.Unlock()
+}
+
+// campaign acquires or renews the lease every renew interval until stopChan is closed
+func (l *leader) campaign(stopChan <-chan struct{}, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(l.cfg.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		l.acquire()
+
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			l.release()
+			return
+		}
+	}
+}
+
+func (l *leader) acquire() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.renewInterval)
+	defer cancel()
+
+	ok, err := l.lease.Acquire(ctx, l.cfg.key, l.id, l.cfg.ttl)
+	if err != nil {
+		// the lease may expire before it can be renewed, stop leading to be safe
+		l.logger.Errorln("cannot acquire leader lease:", err)
+		ok = false
+	}
+
+	l.setLeader(ok)
+}
+
+func (l *leader) release() {
+	if !l.IsLeader() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.renewInterval)
+	defer cancel()
+
+	l.setLeader(false)
+
+	if err := l.lease.Release(ctx, l.cfg.key, l.id); err != nil {
+		l.logger.Errorln("cannot release leader lease:", err)
+	}
+}
+
+// setLeader runs callbacks if leadership changes
+func (l *leader) setLeader(isLeader bool) {
+	l.mu.Lock()
+	changed := l.isLeader != isLeader
+	l.isLeader = isLeader
+
+	callbacks := l.onRevoked
+	if isLeader {
+		callbacks = l.onElected
+	}
+	callbacks = append([]func(){}, callbacks...)
+	l.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if isLeader {
+		l.logger.Infof("%s is elected leader of %s", l.id, l.cfg.key)
+	} else {
+		l.logger.Infof("%s is no longer leader of %s", l.id, l.cfg.key)
+	}
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
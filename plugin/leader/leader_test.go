@@ -0,0 +1,126 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type dbComponent struct {
+	db *gorm.DB
+}
+
+func (c *dbComponent) GetPrefix() string { return "gorm" }
+func (c *dbComponent) Get() interface{}  { return c.db }
+
+func newSQLite(t *testing.T) *dbComponent {
+	db, err := gorm.Open(sqlite.Open("file:leader?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	return &dbComponent{db: db}
+}
+
+func newTestLeader(lease Lease) *leader {
+	l := New("leader", lease)
+	l.cfg = leaderConfig{key: "jobs", ttl: 300 * time.Millisecond, renewInterval: 50 * time.Millisecond}
+	return l
+}
+
+func TestGormLease(t *testing.T) {
+	lease := GormLease(newSQLite(t))
+	ctx := context.Background()
+
+	ok, err := lease.Acquire(ctx, "jobs", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = lease.Acquire(ctx, "jobs", "b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "the lease is held by a")
+
+	ok, err = lease.Acquire(ctx, "jobs", "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok, "a renews its lease")
+
+	require.NoError(t, lease.Release(ctx, "jobs", "b"))
+	ok, _ = lease.Acquire(ctx, "jobs", "b", time.Minute)
+	assert.False(t, ok, "b cannot release the lease of a")
+
+	require.NoError(t, lease.Release(ctx, "jobs", "a"))
+	ok, _ = lease.Acquire(ctx, "jobs", "b", -time.Second)
+	assert.True(t, ok, "b gets the released lease")
+
+	ok, _ = lease.Acquire(ctx, "jobs", "a", time.Minute)
+	assert.True(t, ok, "a takes the expired lease")
+}
+
+func TestElection(t *testing.T) {
+	logger.InitServLogger(false)
+
+	lease := GormLease(newSQLite(t))
+	assert.Equal(t, []string{"gorm"}, New("leader", lease).DependsOn())
+
+	a, b := newTestLeader(lease), newTestLeader(lease)
+
+	events := make(chan string, 10)
+	a.OnElected(func() { events <- "a:elected" })
+	a.OnRevoked(func() { events <- "a:revoked" })
+	b.OnElected(func() { events <- "b:elected" })
+
+	require.NoError(t, a.Run())
+	assert.Equal(t, "a:elected", <-events)
+	assert.True(t, a.IsLeader())
+
+	require.NoError(t, b.Run())
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, b.IsLeader())
+
+	// a releases the lease when it stops, b takes it over
+	<-a.Stop()
+	assert.Equal(t, "a:revoked", <-events)
+	assert.False(t, a.IsLeader())
+
+	select {
+	case e := <-events:
+		assert.Equal(t, "b:elected", e)
+	case <-time.After(time.Second):
+		t.Fatal("b is not elected")
+	}
+
+	<-b.Stop()
+}
+
+func TestConcurrentStop(t *testing.T) {
+	logger.InitServLogger(false)
+
+	l := newTestLeader(GormLease(newSQLite(t)))
+	require.NoError(t, l.Run())
+
+	first, second := l.Stop(), l.Stop()
+	<-first
+	<-second
+	<-l.Stop()
+	assert.False(t, l.IsLeader())
+}
+
+func TestConfigure(t *testing.T) {
+	logger.InitServLogger(false)
+
+	l := New("leader", GormLease(&dbComponent{}))
+	l.cfg = leaderConfig{key: "jobs", ttl: time.Second, renewInterval: time.Second}
+
+	assert.Error(t, l.Configure())
+}
@@ -0,0 +1,96 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultLeaseTable = "leader_leases"
+
+type leaseRecord struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	Holder    string `gorm:"size:191;not null"`
+	ExpiresAt time.Time
+}
+
+type gormLease struct {
+	component Component
+	table     string
+	once      sync.Once
+	err       error
+}
+
+// GormLease is a lease stored as a row of a table in the database of the sdkgorm component,
+// the table is created if it does not exist. Expiration uses the clock of the instances
+func GormLease(db Component, table ...string) *gormLease {
+	t := defaultLeaseTable
+	if len(table) > 0 {
+		t = table[0]
+	}
+
+	return &gormLease{component: db, table: t}
+}
+
+func (g *gormLease) DependsOn() []string {
+	return []string{g.component.GetPrefix()}
+}
+
+func (g *gormLease) db(ctx context.Context) (*gorm.DB, error) {
+	db, _ := g.component.Get().(*gorm.DB)
+	if db == nil {
+		return nil, errors.New("gorm database is not connected")
+	}
+
+	g.once.Do(func() { g.err = db.Table(g.table).AutoMigrate(&leaseRecord{}) })
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	return db.WithContext(ctx).Table(g.table), nil
+}
+
+func (g *gormLease) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	db, err := g.db(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	// renew the lease of the holder or take an expired one
+	result := db.Session(&gorm.Session{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", key, holder, now).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// no one had the lease yet
+	result = db.Session(&gorm.Session{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&leaseRecord{Name: key, Holder: holder, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (g *gormLease) Release(ctx context.Context, key, holder string) error {
+	db, err := g.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("name = ? AND holder = ?", key, holder).Delete(&leaseRecord{}).Error
+}
@@ -0,0 +1,72 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+var (
+	// acquireScript sets the key to the holder if it is free, or extends it if the holder has it
+	acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`)
+
+	// releaseScript deletes the key if the holder has it
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+type redisLease struct {
+	component Component
+}
+
+// RedisLease is a lease stored in a redis key of the sdkredis component
+func RedisLease(rdb Component) *redisLease {
+	return &redisLease{component: rdb}
+}
+
+func (r *redisLease) DependsOn() []string {
+	return []string{r.component.GetPrefix()}
+}
+
+func (r *redisLease) client(ctx context.Context) (*redis.Client, error) {
+	client, _ := r.component.Get().(*redis.Client)
+	if client == nil {
+		return nil, errors.New("redis is not connected")
+	}
+	return client.WithContext(ctx), nil
+}
+
+func (r *redisLease) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	client, err := r.client(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := acquireScript.Run(client, []string{key}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (r *redisLease) Release(ctx context.Context, key, holder string) error {
+	client, err := r.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	return releaseScript.Run(client, []string{key}, holder).Err()
+}
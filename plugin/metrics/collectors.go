@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/go-redis/redis/v7"
+	"github.com/lequocbinh04/go-sdk/plugin/sckio"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Component is an init component holding a client. Ex: sdkredis
+type Component interface {
+	GetPrefix() string
+	Get() interface{}
+}
+
+// WatchRedis collects pool stats of the redis client of rdb, labeled with name.
+// Every watched component must have its own name
+func (m *metrics) WatchRedis(name string, rdb Component) {
+	m.registry.MustRegister(newRedisCollector(name, rdb))
+}
+
+// redisCollector has its own descs, the name label makes them differ from
+// the ones of other redis components
+type redisCollector struct {
+	component Component
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newRedisCollector(name string, rdb Component) *redisCollector {
+	labels := prometheus.Labels{"name": name}
+
+	return &redisCollector{
+		component: rdb,
+		hits: prometheus.NewDesc("redis_pool_hits_total",
+			"Number of times a free connection was found in the pool.", nil, labels),
+		misses: prometheus.NewDesc("redis_pool_misses_total",
+			"Number of times a free connection was not found in the pool.", nil, labels),
+		timeouts: prometheus.NewDesc("redis_pool_timeouts_total",
+			"Number of times a wait timeout occurred.", nil, labels),
+		totalConns: prometheus.NewDesc("redis_pool_connections",
+			"Number of connections in the pool.", nil, labels),
+		idleConns: prometheus.NewDesc("redis_pool_idle_connections",
+			"Number of idle connections in the pool.", nil, labels),
+		staleConns: prometheus.NewDesc("redis_pool_stale_connections_total",
+			"Number of stale connections removed from the pool.", nil, labels),
+	}
+}
+
+func (c *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.timeouts, c.totalConns, c.idleConns, c.staleConns} {
+		ch <- d
+	}
+}
+
+// Collect reads stats of the current client, it changes when the component reloads
+func (c *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	client, _ := c.component.Get().(*redis.Client)
+	if client == nil {
+		return
+	}
+
+	stats := client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
+
+// WatchSocketIO collects the number of connections of the socket server, labeled with name
+func (m *metrics) WatchSocketIO(name string, server sckio.SocketServer) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "socketio_connections",
+		Help:        "Number of open socket.io connections.",
+		ConstLabels: prometheus.Labels{"name": name},
+	}, func() float64 {
+		// nil until the realtime server starts
+		io := server.GetSocketServer()
+		if io == nil {
+			return 0
+		}
+		return float64(io.Count())
+	}))
+}
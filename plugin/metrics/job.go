@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// JobHandler counts attempts and failures of an asyncjob handler, retries included:
+//
+//	asyncjob.NewAsyncJob("sync", l, m.JobHandler("sync", handler))
+func (m *metrics) JobHandler(name string, handler func(ctx context.Context) error) func(ctx context.Context) error {
+	attempts := m.jobAttempts.WithLabelValues(name)
+	failures := m.jobFailures.WithLabelValues(name)
+	duration := m.jobDuration.WithLabelValues(name)
+
+	return func(ctx context.Context) error {
+		start := time.Now()
+		attempts.Inc()
+
+		err := handler(ctx)
+		duration.Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			failures.Inc()
+		}
+		return err
+	}
+}
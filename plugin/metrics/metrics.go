@@ -0,0 +1,165 @@
+// Package metrics collects Prometheus metrics of the service and its components.
+// It implements goservice.MetricsHandler, so they are served on /metrics of the admin server:
+//
+//	m := metrics.New("metrics")
+//	service := goservice.New(goservice.WithInitRunnable(m))
+//	service.HTTPServer().AddHandler(func(engine *gin.Engine) {
+//		engine.Use(m.GinMiddleware())
+//		...
+//	})
+//	_ = db.Use(m.GormPlugin())
+//	ps := m.PubSub(localpb.NewPubSub("pubsub"))
+//	m.WatchRedis("cache", redisComponent)
+//	m.WatchSocketIO("realtime", socketServer)
+//	job := asyncjob.NewAsyncJob("sync", l, m.JobHandler("sync", handler))
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type metrics struct {
+	prefix         string
+	runtimeMetrics bool
+	registry       *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	dbDuration *prometheus.HistogramVec
+	dbErrors   *prometheus.CounterVec
+
+	pubsubPublished *prometheus.CounterVec
+	pubsubConsumed  *prometheus.CounterVec
+
+	jobAttempts *prometheus.CounterVec
+	jobFailures *prometheus.CounterVec
+	jobDuration *prometheus.HistogramVec
+}
+
+// New creates the metrics component, collectors are registered at once,
+// so instrumentation can be set up before the service runs
+func New(prefix string) *metrics {
+	m := &metrics{
+		prefix:   prefix,
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of HTTP requests by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of gorm queries by operation and table.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"operation", "table"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Number of failed gorm queries by operation and table, not found records excluded.",
+		}, []string{"operation", "table"}),
+
+		pubsubPublished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pubsub_published_total",
+			Help: "Number of published events by channel and status.",
+		}, []string{"channel", "status"}),
+		pubsubConsumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pubsub_consumed_total",
+			Help: "Number of consumed events by channel.",
+		}, []string{"channel"}),
+
+		jobAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "job_attempts_total",
+			Help: "Number of job attempts, retries included, by job.",
+		}, []string{"job"}),
+		jobFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "job_attempt_failures_total",
+			Help: "Number of failed job attempts by job.",
+		}, []string{"job"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "job_attempt_duration_seconds",
+			Help:    "Duration of job attempts by job.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"job"}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.dbDuration, m.dbErrors,
+		m.pubsubPublished, m.pubsubConsumed,
+		m.jobAttempts, m.jobFailures, m.jobDuration,
+	)
+
+	return m
+}
+
+func (m *metrics) GetPrefix() string {
+	return m.prefix
+}
+
+func (m *metrics) Get() interface{} {
+	return m
+}
+
+func (m *metrics) Name() string {
+	return m.prefix
+}
+
+func (m *metrics) InitFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&m.runtimeMetrics, fmt.Sprintf("%s-%s", m.prefix, "runtime"), true,
+		"Collect metrics of the Go runtime and the process")
+}
+
+func (m *metrics) Configure() error {
+	return nil
+}
+
+func (m *metrics) Run() error {
+	if m.runtimeMetrics {
+		// registered once, the component may run again on reload
+		for _, c := range []prometheus.Collector{
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		} {
+			if err := m.registry.Register(c); err != nil {
+				if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *metrics) Stop() <-chan bool {
+	c := make(chan bool)
+	go func() { c <- true }()
+	return c
+}
+
+// Registry of the metrics, to register custom collectors
+func (m *metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// MustRegister registers custom collectors, it panics if one is already registered
+func (m *metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// MetricsHandler serves metrics in Prometheus text format, see goservice.MetricsHandler
+func (m *metrics) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
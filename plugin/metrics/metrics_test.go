@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v7"
+	goservice "github.com/lequocbinh04/go-sdk"
+	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New("metrics")
+
+	engine := gin.New()
+	engine.Use(m.GinMiddleware())
+	engine.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/users/:id", "204")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+type note struct {
+	ID   int
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	m := New("metrics")
+
+	db, err := gorm.Open(sqlite.Open("file:metrics?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(m.GormPlugin()))
+	require.NoError(t, db.AutoMigrate(&note{}))
+
+	require.NoError(t, db.Create(&note{Name: "a"}).Error)
+	require.ErrorIs(t, db.First(&note{}, 100).Error, gorm.ErrRecordNotFound)
+	require.Error(t, db.Table("missing").Create(map[string]interface{}{"name": "b"}).Error)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.dbErrors))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.dbErrors.WithLabelValues("create", "missing")))
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(m.dbDuration), 3)
+}
+
+type fakeProvider struct {
+	events chan *pb.Event
+	err    error
+}
+
+func (p *fakeProvider) Publish(ctx context.Context, channel pb.Channel, data *pb.Event) error {
+	return p.err
+}
+
+func (p *fakeProvider) Subscribe(ctx context.Context, channel pb.Channel) (<-chan *pb.Event, func()) {
+	return p.events, func() { close(p.events) }
+}
+
+func TestPubSub(t *testing.T) {
+	m := New("metrics")
+	provider := &fakeProvider{events: make(chan *pb.Event, 2)}
+	ps := m.PubSub(provider)
+
+	require.NoError(t, ps.Publish(context.Background(), "orders", &pb.Event{}))
+	provider.err = errors.New("closed")
+	require.Error(t, ps.Publish(context.Background(), "orders", &pb.Event{}))
+
+	events, closeFn := ps.Subscribe(context.Background(), "orders")
+	provider.events <- &pb.Event{}
+	provider.events <- &pb.Event{}
+	<-events
+	<-events
+	closeFn()
+
+	_, open := <-events
+	assert.False(t, open)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.pubsubPublished.WithLabelValues("orders", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.pubsubPublished.WithLabelValues("orders", "error")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.pubsubConsumed.WithLabelValues("orders")))
+}
+
+// openProvider doesn't close events when the subscription is closed
+type openProvider struct {
+	fakeProvider
+}
+
+func (p *openProvider) Subscribe(ctx context.Context, channel pb.Channel) (<-chan *pb.Event, func()) {
+	return p.events, func() {}
+}
+
+func TestPubSubStopWithoutReading(t *testing.T) {
+	m := New("metrics")
+
+	for name, stop := range map[string]func(cancel, closeFn func()){
+		"close":  func(cancel, closeFn func()) { closeFn() },
+		"cancel": func(cancel, closeFn func()) { cancel() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			provider := &openProvider{fakeProvider{events: make(chan *pb.Event, 1)}}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, closeFn := m.PubSub(provider).Subscribe(ctx, pb.Channel(name))
+			provider.events <- &pb.Event{}
+
+			// the forwarding goroutine waits for a reader
+			assert.Eventually(t, func() bool {
+				return testutil.ToFloat64(m.pubsubConsumed.WithLabelValues(name)) == 1
+			}, time.Second, 10*time.Millisecond)
+			stop(cancel, closeFn)
+
+			assert.Eventually(t, func() bool {
+				select {
+				case _, open := <-events:
+					return !open
+				default:
+					return false
+				}
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestJobHandler(t *testing.T) {
+	m := New("metrics")
+
+	calls := 0
+	handler := m.JobHandler("sync", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	assert.Error(t, handler(context.Background()))
+	assert.NoError(t, handler(context.Background()))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.jobAttempts.WithLabelValues("sync")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.jobFailures.WithLabelValues("sync")))
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := New("metrics")
+	m.runtimeMetrics = true
+	require.NoError(t, m.Run())
+	// runs again on reload
+	require.NoError(t, m.Run())
+
+	m.JobHandler("sync", func(ctx context.Context) error { return nil })(context.Background())
+
+	rec := httptest.NewRecorder()
+	m.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(string(body), `job_attempts_total{job="sync"} 1`))
+	assert.True(t, strings.Contains(string(body), "go_goroutines"))
+}
+
+type redisComponent struct {
+	client *redis.Client
+}
+
+func (c *redisComponent) GetPrefix() string { return "" }
+func (c *redisComponent) Get() interface{}  { return c.client }
+
+func TestWatchRedis(t *testing.T) {
+	m := New("metrics")
+
+	for _, name := range []string{"cache", "session"} {
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+		t.Cleanup(func() { _ = client.Close() })
+		m.WatchRedis(name, &redisComponent{client: client})
+	}
+	// not connected yet
+	m.WatchRedis("queue", &redisComponent{})
+
+	rec := httptest.NewRecorder()
+	m.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	assert.Contains(t, string(body), `redis_pool_connections{name="cache"} 0`)
+	assert.Contains(t, string(body), `redis_pool_connections{name="session"} 0`)
+	assert.NotContains(t, string(body), `name="queue"`)
+
+	assert.Panics(t, func() { m.WatchRedis("cache", &redisComponent{}) })
+}
+
+func TestComponent(t *testing.T) {
+	var _ goservice.PrefixRunnable = New("metrics")
+	var _ goservice.MetricsHandler = New("metrics")
+}
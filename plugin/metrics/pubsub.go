@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+)
+
+type pubsub struct {
+	pb.Provider
+	m *metrics
+}
+
+// PubSub counts events published and consumed by channel through the provider
+func (m *metrics) PubSub(provider pb.Provider) pb.Provider {
+	return &pubsub{Provider: provider, m: m}
+}
+
+func (p *pubsub) Publish(ctx context.Context, channel pb.Channel, data *pb.Event) error {
+	err := p.Provider.Publish(ctx, channel, data)
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	p.m.pubsubPublished.WithLabelValues(string(channel), status).Inc()
+
+	return err
+}
+
+// Subscribe forwards events of the provider, it stops when ctx is done or the returned
+// func is called, even if the subscriber doesn't read anymore
+func (p *pubsub) Subscribe(ctx context.Context, channel pb.Channel) (<-chan *pb.Event, func()) {
+	events, closeFn := p.Provider.Subscribe(ctx, channel)
+	consumed := p.m.pubsubConsumed.WithLabelValues(string(channel))
+
+	c := make(chan *pb.Event)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	go func() {
+		defer close(c)
+
+		for {
+			select {
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				consumed.Inc()
+
+				select {
+				case c <- evt:
+				case <-stop:
+					return
+				case <-ctx.Done():
+					return
+				}
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c, func() {
+		stopOnce.Do(func() { close(stop) })
+		closeFn()
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 	"golang.org/x/oauth2/clientcredentials"
 	"net/http"
 )
@@ -50,11 +51,12 @@ func (o *oauth) Name() string {
 	return o.name
 }
 
-func (o *oauth) InitFlags() {
+func (o *oauth) InitFlags(fs *flag.FlagSet) {
 	prefix := fmt.Sprintf("%s-", o.Name())
-	flag.StringVar(&o.clientConf.ClientSecret, prefix+"client-secret", o.clientConf.ClientSecret, "oauth client secret")
-	flag.StringVar(&o.clientConf.ClientID, prefix+"client-id", o.clientConf.ClientID, "oauth client id")
-	flag.StringVar(&o.clientConf.TokenURL, prefix+"token-url", o.clientConf.TokenURL, "oauth token url")
+	fs.StringVar(&o.clientConf.ClientSecret, prefix+"client-secret", o.clientConf.ClientSecret, "oauth client secret")
+	fs.StringVar(&o.clientConf.ClientID, prefix+"client-id", o.clientConf.ClientID, "oauth client id")
+	fs.StringVar(&o.clientConf.TokenURL, prefix+"token-url", o.clientConf.TokenURL, "oauth token url")
+	secret.MarkFlag(prefix + "client-secret")
 }
 
 func (o *oauth) Configure() error {
@@ -7,6 +7,7 @@ import (
 	"github.com/lequocbinh04/go-sdk/logger"
 	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
 	"sync"
+	"sync/atomic"
 )
 
 type pubsub struct {
@@ -19,7 +20,7 @@ type pubsub struct {
 	messageQueue chan *pb.Event
 	mapChannel   map[pb.Channel][]chan *pb.Event
 	stopChan     chan bool
-	isStopping   bool
+	isStopping   int32
 }
 
 func NewPubsub(prefix string) *pubsub {
@@ -45,11 +46,11 @@ func (ps *pubsub) Name() string {
 	return "pubsub"
 }
 
-func (ps *pubsub) InitFlags() {
+func (ps *pubsub) InitFlags(fs *flag.FlagSet) {
 	pf := ps.GetPrefix()
 
-	flag.BoolVar(&ps.logEnabled, pf+"-log-enabled", true, "Enable logger of pubsub system")
-	flag.BoolVar(&ps.gracefulStop, pf+"-graceful-stop", false, "Enable graceful shutdown")
+	fs.BoolVar(&ps.logEnabled, pf+"-log-enabled", true, "Enable logger of pubsub system")
+	fs.BoolVar(&ps.gracefulStop, pf+"-graceful-stop", false, "Wait until subscribers ack published events on shutdown")
 }
 
 func (ps *pubsub) Configure() error {
@@ -59,7 +60,7 @@ func (ps *pubsub) Configure() error {
 
 func (ps *pubsub) Run() error {
 	_ = ps.Configure()
-	ps.isStopping = false
+	atomic.StoreInt32(&ps.isStopping, 0)
 
 	go ps.listen()
 
@@ -74,10 +75,6 @@ func (ps *pubsub) Stop() <-chan bool {
 	c := make(chan bool)
 
 	go func() {
-		if ps.gracefulStop {
-			ps.wg.Wait()
-		}
-
 		ps.locker.Lock()
 
 		for _, chans := range ps.mapChannel {
@@ -97,22 +94,53 @@ func (ps *pubsub) Stop() <-chan bool {
 	return c
 }
 
+// StopAccepting drops events published from now on
+func (ps *pubsub) StopAccepting(ctx context.Context) error {
+	atomic.StoreInt32(&ps.isStopping, 1)
+	return nil
+}
+
+// Drain waits until subscribers ack all published events if graceful stop is enabled
+func (ps *pubsub) Drain(ctx context.Context) error {
+	if !ps.gracefulStop {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ps.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (ps *pubsub) Publish(ctx context.Context, channel pb.Channel, data *pb.Event) error {
-	if ps.isStopping {
+	if atomic.LoadInt32(&ps.isStopping) == 1 {
 		return nil
 	}
 
 	// Need to know what channel event will push to
 	data.SetChannel(channel)
 
+	// the event is passed in memory, subscribers get the trace context from its headers
+	_, span := pb.StartPublishSpan(ctx, "local", channel, data)
+	defer span.End()
+
+	// The event is in-flight until every subscriber acks it
+	ps.wg.Add(1)
+
 	go func() {
 		ps.messageQueue <- data
 
 		if ps.logEnabled {
 			ps.logger.Debugln(fmt.Sprintf("new event enqueue: %s", data.String()))
 		}
-
-		ps.wg.Add(1)
 	}()
 	return nil
 }
@@ -157,7 +185,7 @@ func (ps *pubsub) listen() {
 		for {
 			select {
 			case <-ps.stopChan:
-				ps.isStopping = true
+				atomic.StoreInt32(&ps.isStopping, 1)
 
 				if ps.logEnabled {
 					ps.logger.Infoln(fmt.Sprintf("stopping..."))
@@ -175,13 +203,19 @@ func (ps *pubsub) listen() {
 				chans, ok := ps.mapChannel[evt.GetChannel()]
 				ps.locker.RUnlock()
 
-				if ok {
-					if len(chans) > 0 {
-						ps.wg.Add(1)
-					}
+				if len(chans) == 0 {
+					ps.wg.Done()
+				} else if len(chans) > 1 {
+					ps.wg.Add(len(chans) - 1)
+				}
 
+				if ok {
 					for _, evtChan := range chans {
-						go func(c chan *pb.Event) { c <- evt }(evtChan)
+						go func(c chan *pb.Event) {
+							span := pb.StartReceiveSpan("local", evt)
+							c <- evt
+							span.End()
+						}(evtChan)
 					}
 
 				}
@@ -0,0 +1,84 @@
+package localpb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPublishPropagatesTraceContext(t *testing.T) {
+	logger.InitServLogger(false)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	ps := NewPubsub("pubsub")
+	require.NoError(t, ps.Run())
+	t.Cleanup(func() { <-ps.Stop() })
+
+	events, closeFn := ps.Subscribe(context.Background(), "orders")
+	defer closeFn()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	require.NoError(t, ps.Publish(ctx, "orders", pb.NewEvent("order created", nil, nil, 1)))
+
+	select {
+	case evt := <-events:
+		evt.DoAck()
+
+		_, consumer := evt.StartSpan(context.Background())
+		defer consumer.End()
+
+		assert.Equal(t, span.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	case <-time.After(time.Second):
+		t.Fatal("event is not delivered")
+	}
+
+	// the delivery is traced by the provider
+	assert.Eventually(t, func() bool {
+		for _, s := range recorder.Ended() {
+			if s.Name() == "orders receive" && s.SpanKind() == trace.SpanKindConsumer {
+				return s.SpanContext().TraceID() == span.SpanContext().TraceID()
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStopAcceptingWhilePublishing(t *testing.T) {
+	logger.InitServLogger(false)
+
+	ps := NewPubsub("pubsub")
+	require.NoError(t, ps.Run())
+	t.Cleanup(func() { <-ps.Stop() })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = ps.Publish(context.Background(), "orders", pb.NewEvent("order created", nil, nil, i))
+		}
+	}()
+
+	require.NoError(t, ps.StopAccepting(context.Background()))
+	<-done
+
+	assert.NoError(t, ps.Publish(context.Background(), "orders", pb.NewEvent("order created", nil, nil, 0)))
+}
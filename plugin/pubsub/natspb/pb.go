@@ -3,12 +3,18 @@ package natspb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"github.com/lequocbinh04/go-sdk/logger"
 	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 	"github.com/nats-io/nats.go"
+	"time"
 )
 
+const drainCheckInterval = 50 * time.Millisecond
+
 type NatsOpt struct {
 	prefix   string
 	server   string
@@ -50,16 +56,17 @@ func (n *natspb) Name() string {
 	return n.name
 }
 
-func (n *natspb) InitFlags() {
+func (n *natspb) InitFlags(fs *flag.FlagSet) {
 	prefix := n.prefix
 	if n.prefix != "" {
 		prefix += "-"
 	}
 
-	flag.StringVar(&n.server, prefix+"nats-server", "", "Nats connect server. Ex: \"nats://..., nats://\"")
-	flag.StringVar(&n.username, prefix+"nats-username", "", "Nats username")
-	flag.StringVar(&n.password, prefix+"nats-password", "", "Nats password")
-	flag.StringVar(&n.token, prefix+"nats-token", "", "Nats token")
+	fs.StringVar(&n.server, prefix+"nats-server", "", "Nats connect server. Ex: \"nats://..., nats://\"")
+	fs.StringVar(&n.username, prefix+"nats-username", "", "Nats username")
+	fs.StringVar(&n.password, prefix+"nats-password", "", "Nats password")
+	fs.StringVar(&n.token, prefix+"nats-token", "", "Nats token")
+	secret.MarkFlag(prefix+"nats-password", prefix+"nats-token")
 }
 
 func (n *natspb) Configure() error {
@@ -67,7 +74,7 @@ func (n *natspb) Configure() error {
 		return nil
 	}
 	n.logger = logger.GetCurrent().GetLogger(n.name)
-	n.logger.Info("Connecting to Nats at ", n.server, " ...")
+	n.logger.Info("Connecting to Nats at ", secret.RedactURI(n.server), " ...")
 
 	var options []nats.Option
 
@@ -76,7 +83,7 @@ func (n *natspb) Configure() error {
 			n.logger.Errorf("Got disconnected! Reason: %q\n", err)
 		}),
 		nats.ReconnectHandler(func(nc *nats.Conn) {
-			n.logger.Errorf("Got reconnected to %v!\n", nc.ConnectedUrl())
+			n.logger.Errorf("Got reconnected to %v!\n", secret.RedactURI(nc.ConnectedUrl()))
 		}),
 		nats.ClosedHandler(func(nc *nats.Conn) {
 			n.logger.Errorf("Connection closed. Reason: %q\n", nc.LastError())
@@ -107,9 +114,10 @@ func (n *natspb) Run() error {
 
 func (n *natspb) Stop() <-chan bool {
 	if n.nc != nil {
-
-		err := n.nc.Drain()
-		if err != nil {
+		if n.nc.IsDraining() || n.nc.IsClosed() {
+			// close what StopAccepting did not drain in time
+			n.nc.Close()
+		} else if err := n.nc.Drain(); err != nil {
 			n.logger.Errorf("Error when drain nats connection: %q\n", err)
 		}
 	}
@@ -120,6 +128,47 @@ func (n *natspb) Stop() <-chan bool {
 	return c
 }
 
+// StopAccepting stops receiving messages of subscriptions, pending ones are still delivered.
+// The connection is closed when they are
+func (n *natspb) StopAccepting(ctx context.Context) error {
+	if n.nc == nil || n.nc.IsClosed() {
+		return nil
+	}
+	return n.nc.Drain()
+}
+
+// Drain waits until pending messages are delivered and the connection is closed
+func (n *natspb) Drain(ctx context.Context) error {
+	if n.nc == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for !n.nc.IsClosed() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (n *natspb) Health(ctx context.Context) error {
+	if n.nc == nil {
+		return errors.New("nats is not connected")
+	}
+
+	if !n.nc.IsConnected() {
+		return fmt.Errorf("nats connection status: %v", n.nc.Status())
+	}
+
+	return nil
+}
+
 func (n *natspb) Publish(ctx context.Context, channel pb.Channel, data *pb.Event) error {
 	dataByte, err := json.Marshal(data.Data)
 
@@ -128,7 +177,21 @@ func (n *natspb) Publish(ctx context.Context, channel pb.Channel, data *pb.Event
 		return err
 	}
 
-	if err := n.nc.Publish(string(channel), dataByte); err != nil {
+	_, span := pb.StartPublishSpan(ctx, "nats", channel, data)
+
+	msg := &nats.Msg{Subject: string(channel), Data: dataByte, Header: nats.Header{}}
+	for k, v := range data.Headers {
+		msg.Header.Set(k, v)
+	}
+
+	err = n.nc.PublishMsg(msg)
+	if errors.Is(err, nats.ErrHeadersNotSupported) {
+		// servers before 2.2 can't carry the trace context
+		err = n.nc.Publish(string(channel), dataByte)
+	}
+	pb.EndSpan(span, err)
+
+	if err != nil {
 		n.logger.Errorln(err)
 		return err
 	}
@@ -150,7 +213,13 @@ func (n *natspb) Subscribe(ctx context.Context, channel pb.Channel) (c <-chan *p
 			RemoteData: msg.Data,
 		}
 
+		for k := range msg.Header {
+			evt.SetHeader(k, msg.Header.Get(k))
+		}
+
+		span := pb.StartReceiveSpan("nats", evt)
 		ch <- evt
+		span.End()
 	})
 
 	if err != nil {
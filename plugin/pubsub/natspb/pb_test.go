@@ -0,0 +1,119 @@
+package natspb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestPubSub connects to an in-process nats server
+func newTestPubSub(t *testing.T, opts server.Options) *natspb {
+	logger.InitServLogger(false)
+
+	opts.Host, opts.Port, opts.NoLog, opts.NoSigs = "127.0.0.1", -1, true, true
+	s, err := server.NewServer(&opts)
+	require.NoError(t, err)
+
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	t.Cleanup(s.Shutdown)
+
+	ps := NewNatsPubSub("nats", "")
+	ps.server = s.ClientURL()
+	require.NoError(t, ps.Run())
+	t.Cleanup(func() { <-ps.Stop() })
+
+	return ps
+}
+
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	return recorder
+}
+
+func receive(t *testing.T, events <-chan *pb.Event) *pb.Event {
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("event is not delivered")
+		return nil
+	}
+}
+
+func TestPublishPropagatesTraceContext(t *testing.T) {
+	recorder := setupTracing(t)
+	ps := newTestPubSub(t, server.Options{})
+
+	events, closeFn := ps.Subscribe(context.Background(), "orders")
+	defer closeFn()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	require.NoError(t, ps.Publish(ctx, "orders", pb.NewEvent("order created", nil, nil, 1)))
+
+	evt := receive(t, events)
+	assert.Equal(t, "1", string(evt.RemoteData))
+	assert.NotEmpty(t, evt.Header("traceparent"))
+
+	// subscribers trace their work with the context of the event
+	evtCtx := evt.Context(context.Background())
+	assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(evtCtx).TraceID())
+
+	var publish, receiveSpan sdktrace.ReadOnlySpan
+	assert.Eventually(t, func() bool {
+		for _, s := range recorder.Ended() {
+			switch s.Name() {
+			case "orders publish":
+				publish = s
+			case "orders receive":
+				receiveSpan = s
+			}
+		}
+		return publish != nil && receiveSpan != nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, trace.SpanKindProducer, publish.SpanKind())
+	assert.Equal(t, span.SpanContext().SpanID(), publish.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindConsumer, receiveSpan.SpanKind())
+	assert.Equal(t, publish.SpanContext().SpanID(), receiveSpan.Parent().SpanID())
+}
+
+func TestPublishWithoutHeaderSupport(t *testing.T) {
+	setupTracing(t)
+	ps := newTestPubSub(t, server.Options{NoHeaderSupport: true})
+
+	events, closeFn := ps.Subscribe(context.Background(), "orders")
+	defer closeFn()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	// the event is published without the trace context
+	require.NoError(t, ps.Publish(ctx, "orders", pb.NewEvent("order created", nil, nil, 1)))
+
+	evt := receive(t, events)
+	assert.Equal(t, "1", string(evt.RemoteData))
+	assert.Empty(t, evt.Headers)
+	assert.False(t, trace.SpanContextFromContext(evt.Context(context.Background())).IsValid())
+}
@@ -12,6 +12,9 @@ type Channel string
 
 type Provider interface {
 	Publish(ctx context.Context, channel Channel, data *Event) error
+	// Subscribe delivers the events of channel to c.
+	// The receive span of the provider ends when an event is sent to c,
+	// subscribers continue the trace with evt.Context(ctx) or evt.StartSpan(ctx)
 	Subscribe(ctx context.Context, channel Channel) (c <-chan *Event, close func())
 }
 
@@ -44,6 +47,8 @@ type Event struct {
 	Ack        func()
 	CreatedAt  time.Time `json:"created_at"`
 	RemoteData []byte    `json:"remote_data"`
+	// Headers carry metadata of the event like the trace context of the publisher, see StartPublishSpan
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 func (e Event) String() string {
@@ -81,6 +86,14 @@ func (e *Event) GetWhen() interface{}       { return e.CreatedAt }
 func (e *Event) DoAck()                     { e.Ack() }
 func (e *Event) SetChannel(c Channel)       { e.Channel = c }
 func (e *Event) SetAck(f func())            { e.Ack = f }
+func (e *Event) Header(key string) string   { return e.Headers[key] }
+
+func (e *Event) SetHeader(key, value string) {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[key] = value
+}
 
 func NewEvent(title string, author, receiver Entity, data interface{}) *Event {
 	return &Event{
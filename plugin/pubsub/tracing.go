@@ -0,0 +1,97 @@
+package pb
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+
+// StartPublishSpan starts the producer span of evt published to channel with system (Ex: nats)
+// and injects its context into the headers of evt. Providers end it when the event is published:
+//
+//	ctx, span := pb.StartPublishSpan(ctx, "nats", channel, evt)
+//	defer span.End()
+func StartPublishSpan(ctx context.Context, system string, channel Channel, evt *Event) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, string(channel)+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(system, channel, evt)...),
+	)
+
+	if evt.Headers == nil {
+		evt.Headers = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(evt.Headers))
+
+	return ctx, span
+}
+
+// EndSpan records err on span then ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Context returns ctx with the trace context of the publisher carried by the headers
+func (e *Event) Context(ctx context.Context) context.Context {
+	if len(e.Headers) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(e.Headers))
+}
+
+// StartReceiveSpan starts the consumer span of evt delivered to a subscriber by a provider
+// with system (Ex: nats), as a child of the publisher span.
+// Providers end it once the event is handed to the subscriber:
+//
+//	span := pb.StartReceiveSpan("nats", evt)
+//	ch <- evt
+//	span.End()
+//
+// The span only covers the delivery, it does not include the work of the subscriber.
+// Subscribers trace their work with evt.Context(ctx) or evt.StartSpan(ctx).
+func StartReceiveSpan(system string, evt *Event) trace.Span {
+	_, span := otel.Tracer(tracerName).Start(evt.Context(context.Background()), string(evt.Channel)+" receive",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes(system, evt.Channel, evt)...),
+	)
+	return span
+}
+
+// StartSpan starts the consumer span processing the event, as a child of the publisher span.
+// Providers only trace the delivery, see StartReceiveSpan. Subscribers end it when the event is processed:
+//
+//	for evt := range events {
+//		ctx, span := evt.StartSpan(context.Background())
+//		err := handle(ctx, evt)
+//		pb.EndSpan(span, err)
+//	}
+func (e *Event) StartSpan(ctx context.Context) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(e.Context(ctx), string(e.Channel)+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes("", e.Channel, e)...),
+	)
+}
+
+func messagingAttributes(system string, channel Channel, evt *Event) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.MessagingDestinationName(string(channel))}
+
+	if system != "" {
+		attrs = append(attrs, semconv.MessagingSystem(system))
+	}
+
+	if evt.Id != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(evt.Id))
+	}
+
+	return attrs
+}
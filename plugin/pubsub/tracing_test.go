@@ -0,0 +1,75 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	return recorder
+}
+
+func TestEventTracePropagation(t *testing.T) {
+	recorder := setupTracing(t)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	evt := NewEvent("order created", nil, nil, nil)
+
+	_, span := StartPublishSpan(ctx, "nats", "orders", evt)
+	EndSpan(span, nil)
+	parent.End()
+
+	require.NotEmpty(t, evt.Header("traceparent"))
+
+	// delivered to a subscriber, which only gets headers
+	received := &Event{Channel: "orders", Headers: evt.Headers}
+	StartReceiveSpan("nats", received).End()
+	_, consumer := received.StartSpan(context.Background())
+	EndSpan(consumer, errors.New("cannot handle"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	publish, receive, process := spans[0], spans[2], spans[3]
+	assert.Equal(t, "orders publish", publish.Name())
+	assert.Equal(t, trace.SpanKindProducer, publish.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), publish.Parent().SpanID())
+
+	assert.Equal(t, "orders receive", receive.Name())
+	assert.Equal(t, trace.SpanKindConsumer, receive.SpanKind())
+	assert.Equal(t, publish.SpanContext().SpanID(), receive.Parent().SpanID())
+
+	assert.Equal(t, "orders process", process.Name())
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+	assert.Equal(t, parent.SpanContext().TraceID(), process.SpanContext().TraceID())
+	assert.Equal(t, publish.SpanContext().SpanID(), process.Parent().SpanID())
+	assert.Equal(t, codes.Error, process.Status().Code)
+}
+
+func TestEventContextWithoutHeaders(t *testing.T) {
+	setupTracing(t)
+
+	evt := &Event{Channel: "orders"}
+	ctx := evt.Context(context.Background())
+
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
@@ -0,0 +1,304 @@
+// Package consul registers services on Consul and resolves them with its HTTP API
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/plugin/registry"
+	"github.com/lequocbinh04/go-sdk/util/secret"
+)
+
+const (
+	defaultCheckInterval   = 10 * time.Second
+	defaultDeregisterAfter = time.Minute
+	requestTimeout         = 5 * time.Second
+)
+
+var ErrConsulDisabled = errors.New("consul is disabled")
+
+type consulConfig struct {
+	addr            string
+	token           string
+	advertiseAddr   string
+	tags            string
+	checkInterval   time.Duration
+	deregisterAfter time.Duration
+}
+
+type consul struct {
+	prefix string
+	logger logger.Logger
+	cfg    consulConfig
+	client *http.Client
+}
+
+// New creates a Consul registry and resolver, its prefix is "consul" by default
+func New(prefix ...string) *consul {
+	pre := "consul"
+
+	if len(prefix) > 0 {
+		pre = prefix[0]
+	}
+
+	return &consul{
+		prefix: pre,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *consul) GetPrefix() string {
+	return c.prefix
+}
+
+func (c *consul) Get() interface{} {
+	return c
+}
+
+func (c *consul) Name() string {
+	return c.prefix
+}
+
+func (c *consul) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.cfg.addr, fmt.Sprintf("%s-%s", c.prefix, "addr"), "", "Consul HTTP API address. Ex: http://127.0.0.1:8500")
+	fs.StringVar(&c.cfg.token, fmt.Sprintf("%s-%s", c.prefix, "token"), "", "Consul ACL token")
+	fs.StringVar(&c.cfg.advertiseAddr, fmt.Sprintf("%s-%s", c.prefix, "advertise-addr"), "",
+		"Address registered for the service. Default: http server bind address or the first private IP")
+	fs.StringVar(&c.cfg.tags, fmt.Sprintf("%s-%s", c.prefix, "tags"), "", "Tags of the service, separated by commas")
+	fs.DurationVar(&c.cfg.checkInterval, fmt.Sprintf("%s-%s", c.prefix, "check-interval"), defaultCheckInterval,
+		"Interval of the health check")
+	fs.DurationVar(&c.cfg.deregisterAfter, fmt.Sprintf("%s-%s", c.prefix, "deregister-after"), defaultDeregisterAfter,
+		"Deregister the service when its health check is critical for this duration")
+	secret.MarkFlag(fmt.Sprintf("%s-%s", c.prefix, "token"))
+}
+
+func (c *consul) isDisabled() bool {
+	return c.cfg.addr == ""
+}
+
+func (c *consul) Configure() error {
+	c.logger = logger.GetCurrent().GetLogger(c.prefix)
+
+	if c.isDisabled() {
+		return nil
+	}
+
+	if _, err := url.Parse(c.cfg.addr); err != nil {
+		return fmt.Errorf("invalid consul address: %w", err)
+	}
+
+	return nil
+}
+
+func (c *consul) Run() error {
+	return c.Configure()
+}
+
+func (c *consul) Stop() <-chan bool {
+	ch := make(chan bool)
+	go func() { ch <- true }()
+	return ch
+}
+
+// Health checks the Consul cluster has a leader
+func (c *consul) Health(ctx context.Context) error {
+	if c.isDisabled() {
+		return nil
+	}
+
+	var leader string
+	if err := c.do(ctx, http.MethodGet, "/v1/status/leader", nil, &leader); err != nil {
+		return err
+	}
+
+	if leader == "" {
+		return errors.New("consul has no leader")
+	}
+
+	return nil
+}
+
+// AdvertiseAddr returns the address to register if it is configured
+func (c *consul) AdvertiseAddr() string {
+	return c.cfg.advertiseAddr
+}
+
+type agentServiceCheck struct {
+	HTTP                           string `json:"HTTP"`
+	Interval                       string `json:"Interval"`
+	Timeout                        string `json:"Timeout"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+type agentServiceRegistration struct {
+	ID      string             `json:"ID"`
+	Name    string             `json:"Name"`
+	Tags    []string           `json:"Tags,omitempty"`
+	Address string             `json:"Address"`
+	Port    int                `json:"Port"`
+	Meta    map[string]string  `json:"Meta,omitempty"`
+	Check   *agentServiceCheck `json:"Check,omitempty"`
+}
+
+// Register registers instance on the local Consul agent with an HTTP health check
+func (c *consul) Register(ctx context.Context, instance *registry.Instance) error {
+	if c.isDisabled() {
+		c.logger.Infoln("consul is disabled, skip registering", instance.Name)
+		return nil
+	}
+
+	meta := map[string]string{}
+	for k, v := range instance.Meta {
+		meta[k] = v
+	}
+	if instance.Version != "" {
+		meta["version"] = instance.Version
+	}
+
+	reg := agentServiceRegistration{
+		ID:      instance.ID,
+		Name:    instance.Name,
+		Tags:    append(append([]string{}, instance.Tags...), c.configTags()...),
+		Address: instance.Address,
+		Port:    instance.Port,
+		Meta:    meta,
+	}
+
+	if instance.HealthCheckURL != "" {
+		reg.Check = &agentServiceCheck{
+			HTTP:                           instance.HealthCheckURL,
+			Interval:                       c.cfg.checkInterval.String(),
+			Timeout:                        requestTimeout.String(),
+			DeregisterCriticalServiceAfter: c.cfg.deregisterAfter.String(),
+		}
+	}
+
+	if err := c.do(ctx, http.MethodPut, "/v1/agent/service/register", reg, nil); err != nil {
+		return err
+	}
+
+	c.logger.Infof("registered %s (%s) at %s:%d", instance.Name, instance.ID, instance.Address, instance.Port)
+	return nil
+}
+
+// Deregister removes the instance with id from the local Consul agent
+func (c *consul) Deregister(ctx context.Context, id string) error {
+	if c.isDisabled() {
+		return nil
+	}
+
+	if err := c.do(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil, nil); err != nil {
+		return err
+	}
+
+	c.logger.Infof("deregistered %s", id)
+	return nil
+}
+
+type serviceEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		ID      string            `json:"ID"`
+		Service string            `json:"Service"`
+		Tags    []string          `json:"Tags"`
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+}
+
+// Resolve returns instances of the service with name which pass their health checks
+func (c *consul) Resolve(ctx context.Context, name string) ([]registry.Instance, error) {
+	if c.isDisabled() {
+		return nil, ErrConsulDisabled
+	}
+
+	var entries []serviceEntry
+	if err := c.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(name)+"?passing=true", nil, &entries); err != nil {
+		return nil, err
+	}
+
+	instances := make([]registry.Instance, 0, len(entries))
+	for _, e := range entries {
+		addr := e.Service.Address
+		if addr == "" {
+			addr = e.Node.Address
+		}
+
+		instances = append(instances, registry.Instance{
+			ID:      e.Service.ID,
+			Name:    e.Service.Service,
+			Version: e.Service.Meta["version"],
+			Address: addr,
+			Port:    e.Service.Port,
+			Tags:    e.Service.Tags,
+			Meta:    e.Service.Meta,
+		})
+	}
+
+	return instances, nil
+}
+
+func (c *consul) configTags() []string {
+	var tags []string
+	for _, tag := range strings.Split(c.cfg.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// do sends a request to the Consul HTTP API, body and out are encoded as JSON
+func (c *consul) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.addr, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.cfg.token != "" {
+		req.Header.Set("X-Consul-Token", c.cfg.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("consul %s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	goservice "github.com/lequocbinh04/go-sdk"
+	"github.com/lequocbinh04/go-sdk/servicetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul is a stand-in of the Consul agent HTTP API
+type fakeConsul struct {
+	sync.Mutex
+	services map[string]agentServiceRegistration
+	token    string
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.token = r.Header.Get("X-Consul-Token")
+
+	switch {
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/agent/service/register":
+		var reg agentServiceRegistration
+		if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.services[reg.ID] = reg
+
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		delete(f.services, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		var entries []serviceEntry
+		for _, reg := range f.services {
+			if reg.Name != strings.TrimPrefix(r.URL.Path, "/v1/health/service/") {
+				continue
+			}
+
+			var e serviceEntry
+			e.Node.Address = "10.0.0.1"
+			e.Service.ID, e.Service.Service, e.Service.Port = reg.ID, reg.Name, reg.Port
+			e.Service.Meta, e.Service.Tags = reg.Meta, reg.Tags
+			entries = append(entries, e)
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/status/leader":
+		_ = json.NewEncoder(w).Encode("10.0.0.1:8300")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) registered() []agentServiceRegistration {
+	f.Lock()
+	defer f.Unlock()
+
+	var regs []agentServiceRegistration
+	for _, reg := range f.services {
+		regs = append(regs, reg)
+	}
+	return regs
+}
+
+func TestRegistry(t *testing.T) {
+	fake := &fakeConsul{services: map[string]agentServiceRegistration{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	sv := servicetest.Start(t,
+		goservice.WithName("demo"),
+		goservice.WithVersion("1.0.0"),
+		goservice.WithInitRunnable(New()),
+		goservice.WithRegistry("consul"),
+		servicetest.Config("consul-addr", srv.URL),
+		servicetest.Config("consul-token", "secret"),
+		servicetest.Config("consul-advertise-addr", "127.0.0.1"),
+		servicetest.Config("consul-tags", "api, v1"),
+	)
+
+	assert.True(t, sv.IsRegistered())
+
+	regs := fake.registered()
+	if assert.Len(t, regs, 1) {
+		reg := regs[0]
+		assert.Equal(t, "demo", reg.Name)
+		assert.Equal(t, "127.0.0.1", reg.Address)
+		assert.Equal(t, []string{"api", "v1"}, reg.Tags)
+		assert.Equal(t, "1.0.0", reg.Meta["version"])
+		assert.Equal(t, sv.URL("/readyz"), reg.Check.HTTP)
+		assert.Equal(t, "secret", fake.token)
+	}
+
+	c := goservice.MustGet[*consul](sv, "consul")
+	assert.NoError(t, c.Health(context.Background()))
+
+	instances, err := c.Resolve(context.Background(), "demo")
+	if assert.NoError(t, err) && assert.Len(t, instances, 1) {
+		assert.Equal(t, "10.0.0.1", instances[0].Address)
+		assert.Equal(t, "1.0.0", instances[0].Version)
+	}
+
+	sv.Stop()
+	assert.False(t, sv.IsRegistered())
+	assert.Empty(t, fake.registered())
+}
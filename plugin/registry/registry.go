@@ -0,0 +1,30 @@
+// Package registry defines service discovery used by goservice.WithRegistry.
+// See registry/consul for a Consul implementation.
+package registry
+
+import "context"
+
+// Instance is a running instance of a service
+type Instance struct {
+	ID      string
+	Name    string
+	Version string
+	// Address and Port the instance serves HTTP on
+	Address string
+	Port    int
+	Tags    []string
+	Meta    map[string]string
+	// HealthCheckURL is polled by the registry, an instance is healthy while it returns 200
+	HealthCheckURL string
+}
+
+// Registry registers instances of services
+type Registry interface {
+	Register(ctx context.Context, instance *Instance) error
+	Deregister(ctx context.Context, id string) error
+}
+
+// Resolver looks up healthy instances of a service
+type Resolver interface {
+	Resolve(ctx context.Context, name string) ([]Instance, error)
+}
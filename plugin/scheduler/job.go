@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	StatusIdle      = "idle"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// JobStatus of a job and its last run
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Status       string        `json:"status"`
+	LastRun      time.Time     `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	NextRun      time.Time     `json:"next_run,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	Skipped      int           `json:"skipped"`
+}
+
+// intervalSchedule runs a job every duration, unlike cron.Every it keeps sub-second durations
+type intervalSchedule time.Duration
+
+func (i intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	handler  Handler
+
+	mu           sync.Mutex
+	running      bool
+	lastStatus   string
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+	nextRun      time.Time
+	runs         int
+	failures     int
+	skipped      int
+}
+
+func (j *job) setNext(t time.Time) {
+	j.mu.Lock()
+	j.nextRun = t
+	j.mu.Unlock()
+}
+
+// tryStart marks the job as running, it returns false if it is already running
+func (j *job) tryStart() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.running {
+		j.skipped++
+		return false
+	}
+
+	j.running = true
+	return true
+}
+
+func (j *job) finish(startedAt time.Time, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.running = false
+	j.runs++
+	j.lastRun = startedAt
+	j.lastDuration = time.Since(startedAt)
+	j.lastErr = err
+	j.lastStatus = StatusSucceeded
+
+	if err != nil {
+		j.failures++
+		j.lastStatus = StatusFailed
+	}
+}
+
+// cancel marks the job as not running without recording its run
+func (j *job) cancel() {
+	j.mu.Lock()
+	j.running = false
+	j.mu.Unlock()
+}
+
+func (j *job) status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	st := JobStatus{
+		Name:         j.name,
+		Schedule:     j.spec,
+		Status:       StatusIdle,
+		LastRun:      j.lastRun,
+		LastDuration: j.lastDuration,
+		NextRun:      j.nextRun,
+		Runs:         j.runs,
+		Failures:     j.failures,
+		Skipped:      j.skipped,
+	}
+
+	if j.lastStatus != "" {
+		st.Status = j.lastStatus
+	}
+
+	if j.running {
+		st.Status = StatusRunning
+	}
+
+	if j.lastErr != nil {
+		st.LastError = j.lastErr.Error()
+	}
+
+	return st
+}
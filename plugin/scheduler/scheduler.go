@@ -0,0 +1,280 @@
+// Package scheduler runs jobs on cron expressions or fixed intervals.
+// Each run goes through asyncjob, so a failed run is retried, and a job never
+// runs twice at the same time: a tick is skipped while the previous run is not finished.
+//
+//	sched := scheduler.New("scheduler")
+//	_ = sched.AddCron("cleanup", "0 3 * * *", cleanup)
+//	_ = sched.AddInterval("sync", time.Minute, sync)
+//	service := goservice.New(goservice.WithInitRunnable(sched))
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/asyncjob"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrJobDuplicated   = errors.New("job is duplicated")
+	ErrInvalidInterval = errors.New("interval must be positive")
+
+	// cron expressions with 5 fields, or 6 fields starting with seconds,
+	// and descriptors. Ex: @hourly, @every 10m
+	cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour |
+		cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+)
+
+// Handler of a job, ctx is canceled when the scheduler stops
+type Handler func(ctx context.Context) error
+
+type schedulerConfig struct {
+	timezone       string
+	retryDurations string
+}
+
+type scheduler struct {
+	prefix   string
+	logger   logger.Logger
+	cfg      schedulerConfig
+	location *time.Location
+	retries  []time.Duration
+
+	mu   sync.RWMutex
+	jobs []*job
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(prefix string) *scheduler {
+	return &scheduler{prefix: prefix}
+}
+
+// AddCron adds a job run on a cron expression. Ex: "*/5 * * * *", "@daily"
+func (s *scheduler) AddCron(name, spec string, handler Handler) error {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	return s.add(&job{name: name, spec: spec, schedule: schedule, handler: handler})
+}
+
+// AddInterval adds a job run every interval, the first run is after one interval
+func (s *scheduler) AddInterval(name string, every time.Duration, handler Handler) error {
+	if every <= 0 {
+		return fmt.Errorf("job %s: %w", name, ErrInvalidInterval)
+	}
+
+	return s.add(&job{name: name, spec: "@every " + every.String(), schedule: intervalSchedule(every), handler: handler})
+}
+
+func (s *scheduler) add(j *job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.jobs {
+		if existing.name == j.name {
+			return fmt.Errorf("job %s: %w", j.name, ErrJobDuplicated)
+		}
+	}
+
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+// Jobs returns status of all jobs sorted by name
+func (s *scheduler) Jobs() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status())
+	}
+
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
+
+// Job returns status of the job with name
+func (s *scheduler) Job(name string) (JobStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j.status(), true
+		}
+	}
+
+	return JobStatus{}, false
+}
+
+func (s *scheduler) GetPrefix() string {
+	return s.prefix
+}
+
+func (s *scheduler) Get() interface{} {
+	return s
+}
+
+func (s *scheduler) Name() string {
+	return s.prefix
+}
+
+func (s *scheduler) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.cfg.timezone, fmt.Sprintf("%s-%s", s.prefix, "timezone"), "Local",
+		"Timezone of cron expressions. Ex: UTC, Asia/Ho_Chi_Minh")
+	fs.StringVar(&s.cfg.retryDurations, fmt.Sprintf("%s-%s", s.prefix, "retry-durations"), "5s,15s,1m",
+		"Comma-separated durations to wait before retrying a failed run, empty to not retry")
+}
+
+func (s *scheduler) Configure() error {
+	s.logger = logger.GetCurrent().GetLogger(s.prefix)
+
+	location, err := time.LoadLocation(s.cfg.timezone)
+	if err != nil {
+		return fmt.Errorf("scheduler timezone: %w", err)
+	}
+
+	retries, err := parseDurations(s.cfg.retryDurations)
+	if err != nil {
+		return fmt.Errorf("scheduler retry durations: %w", err)
+	}
+
+	s.location = location
+	s.retries = retries
+	return nil
+}
+
+func parseDurations(s string) ([]time.Duration, error) {
+	var durations []time.Duration
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+
+	return durations, nil
+}
+
+func (s *scheduler) Run() error {
+	if err := s.Configure(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	return nil
+}
+
+// Stop scheduling jobs, running jobs are canceled and waited for
+func (s *scheduler) Stop() <-chan bool {
+	c := make(chan bool)
+
+	go func() {
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.wg.Wait()
+		c <- true
+	}()
+
+	return c
+}
+
+// loop waits for the next tick of the job until ctx is canceled
+func (s *scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		next := j.schedule.Next(time.Now().In(s.location))
+		j.setNext(next)
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !j.tryStart() {
+			s.logger.Warnf("job %s is skipped, its previous run is not finished", j.name)
+			continue
+		}
+
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			s.execute(ctx, j)
+		}()
+	}
+}
+
+func (s *scheduler) execute(ctx context.Context, j *job) {
+	startedAt := time.Now()
+
+	var lastErr error
+	aj := asyncjob.NewAsyncJob(j.name, s.logger, func(ctx context.Context) (err error) {
+		// a panic fails the attempt instead of crashing the service
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger.Errorf("job %s panicked: %v\n%s", j.name, r, debug.Stack())
+				err = fmt.Errorf("panic: %v", r)
+			}
+			lastErr = err
+		}()
+
+		return j.handler(ctx)
+	})
+	aj.SetRetryDurations(s.retries)
+
+	err := asyncjob.Compose(false, s.logger, aj).Run(ctx)
+	if ctx.Err() != nil {
+		// the scheduler is stopping, the run is not a failure of the job
+		s.logger.Warnf("job %s is canceled", j.name)
+		j.cancel()
+		return
+	}
+
+	if err != nil {
+		if lastErr != nil {
+			err = lastErr
+		}
+		s.logger.Errorf("job %s has failed: %s", j.name, err)
+	}
+
+	j.finish(startedAt, err)
+}
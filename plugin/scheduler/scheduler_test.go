@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler() *scheduler {
+	logger.InitServLogger(false)
+
+	s := New("scheduler")
+	s.cfg = schedulerConfig{timezone: "UTC", retryDurations: "10ms"}
+	return s
+}
+
+func TestAddJobs(t *testing.T) {
+	s := newTestScheduler()
+	noop := func(ctx context.Context) error { return nil }
+
+	assert.NoError(t, s.AddCron("cleanup", "0 3 * * *", noop))
+	assert.NoError(t, s.AddCron("report", "*/30 * * * * *", noop))
+	assert.NoError(t, s.AddCron("hourly", "@hourly", noop))
+	assert.Error(t, s.AddCron("invalid", "* * *", noop))
+	assert.ErrorIs(t, s.AddCron("cleanup", "@daily", noop), ErrJobDuplicated)
+	assert.ErrorIs(t, s.AddInterval("sync", 0, noop), ErrInvalidInterval)
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 3)
+	assert.Equal(t, "cleanup", jobs[0].Name)
+	assert.Equal(t, StatusIdle, jobs[0].Status)
+}
+
+func TestRunAndRetry(t *testing.T) {
+	s := newTestScheduler()
+
+	var calls int32
+	succeeded := make(chan struct{}, 10)
+	require.NoError(t, s.AddInterval("sync", 20*time.Millisecond, func(ctx context.Context) error {
+		// every other call fails and is retried
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			return errors.New("timeout")
+		}
+		succeeded <- struct{}{}
+		return nil
+	}))
+
+	require.NoError(t, s.Run())
+
+	// stop once two runs succeeded after their retry
+	for i := 0; i < 2; i++ {
+		select {
+		case <-succeeded:
+		case <-time.After(time.Second):
+			t.Fatal("job is not retried")
+		}
+	}
+
+	assert.Eventually(t, func() bool {
+		st, _ := s.Job("sync")
+		return st.Runs >= 2
+	}, time.Second, time.Millisecond)
+
+	<-s.Stop()
+
+	st, ok := s.Job("sync")
+	require.True(t, ok)
+	assert.Equal(t, StatusSucceeded, st.Status)
+	assert.Zero(t, st.Failures)
+	assert.False(t, st.LastRun.IsZero())
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(4))
+}
+
+func TestCanceledRunIsNotFailure(t *testing.T) {
+	s := newTestScheduler()
+
+	started := make(chan struct{}, 1)
+	require.NoError(t, s.AddInterval("export", 10*time.Millisecond, func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	require.NoError(t, s.Run())
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("job is not run")
+	}
+	<-s.Stop()
+
+	st, _ := s.Job("export")
+	assert.Equal(t, StatusIdle, st.Status)
+	assert.Zero(t, st.Runs)
+	assert.Zero(t, st.Failures)
+}
+
+func TestPanicIsFailure(t *testing.T) {
+	s := newTestScheduler()
+
+	require.NoError(t, s.AddInterval("import", 10*time.Millisecond, func(ctx context.Context) error {
+		var m map[string]int
+		m["a"] = 1
+		return nil
+	}))
+
+	require.NoError(t, s.Run())
+
+	assert.Eventually(t, func() bool {
+		st, _ := s.Job("import")
+		return st.Failures >= 1
+	}, time.Second, time.Millisecond)
+
+	<-s.Stop()
+
+	st, _ := s.Job("import")
+	assert.Equal(t, StatusFailed, st.Status)
+	assert.Contains(t, st.LastError, "panic")
+}
+
+func TestNoOverlap(t *testing.T) {
+	s := newTestScheduler()
+
+	var running, maxRunning int32
+	require.NoError(t, s.AddInterval("slow", 10*time.Millisecond, func(ctx context.Context) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		if n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}))
+
+	require.NoError(t, s.Run())
+
+	assert.Eventually(t, func() bool {
+		st, _ := s.Job("slow")
+		return st.Runs >= 2 && st.Skipped > 0
+	}, time.Second, 10*time.Millisecond)
+
+	<-s.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxRunning))
+}
+
+func TestStopCancelsRetries(t *testing.T) {
+	s := newTestScheduler()
+	s.cfg.retryDurations = "1h"
+
+	failed := make(chan struct{}, 1)
+	require.NoError(t, s.AddInterval("failing", 10*time.Millisecond, func(ctx context.Context) error {
+		select {
+		case failed <- struct{}{}:
+		default:
+		}
+		return errors.New("unavailable")
+	}))
+
+	require.NoError(t, s.Run())
+	<-failed
+
+	select {
+	case <-s.Stop():
+	case <-time.After(time.Second):
+		t.Fatal("scheduler does not stop while a job is waiting to retry")
+	}
+
+	// the run is canceled before its retry, it is not recorded as a failure
+	st, _ := s.Job("failing")
+	assert.NotEqual(t, StatusFailed, st.Status)
+	assert.Zero(t, st.Failures)
+}
@@ -1,6 +1,7 @@
 package sckio
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -8,7 +9,9 @@ import (
 	goservice "github.com/lequocbinh04/go-sdk"
 	"github.com/lequocbinh04/go-sdk/logger"
 	"log"
+	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
 type SocketServer interface {
@@ -31,6 +34,8 @@ type sckServer struct {
 	logger  logger.Logger
 	storage map[int64][]AppSocket
 	locker  *sync.RWMutex
+	// new socket connections are rejected after StopAccepting
+	isStopping int32
 }
 
 func New(name string) *sckServer {
@@ -65,10 +70,24 @@ func (s *sckServer) StartRealtimeServer(engine *gin.Engine, sc goservice.Service
 
 	go server.Serve()
 
-	engine.GET("/socket.io/*any", gin.WrapH(server))
-	engine.POST("/socket.io/*any", gin.WrapH(server))
-	engine.Handle("WS", "/socket.io/*any", gin.WrapH(server))
-	engine.Handle("WSS", "/socket.io/*any", gin.WrapH(server))
+	handler := s.handler(server)
+	engine.GET("/socket.io/*any", handler)
+	engine.POST("/socket.io/*any", handler)
+	engine.Handle("WS", "/socket.io/*any", handler)
+	engine.Handle("WSS", "/socket.io/*any", handler)
+}
+
+// handler serves socket.io requests until StopAccepting
+func (s *sckServer) handler(server *socketio.Server) gin.HandlerFunc {
+	serve := gin.WrapH(server)
+
+	return func(c *gin.Context) {
+		if atomic.LoadInt32(&s.isStopping) == 1 {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		serve(c)
+	}
 }
 
 func (s *sckServer) UserSockets(userId int64) []AppSocket {
@@ -133,9 +152,9 @@ func (s *sckServer) Name() string {
 	return s.Config.Name
 }
 
-func (s *sckServer) InitFlags() {
+func (s *sckServer) InitFlags(fs *flag.FlagSet) {
 	pre := s.GetPrefix()
-	flag.IntVar(&s.MaxConnection, fmt.Sprintf("%s-max-connection", pre), 2000, "socket max connection")
+	fs.IntVar(&s.MaxConnection, fmt.Sprintf("%s-max-connection", pre), 2000, "socket max connection")
 }
 
 func (s *sckServer) Configure() error {
@@ -149,6 +168,37 @@ func (s *sckServer) Run() error {
 
 func (s *sckServer) Stop() <-chan bool {
 	c := make(chan bool)
-	go func() { c <- true }()
+	go func() {
+		atomic.StoreInt32(&s.isStopping, 1)
+		if s.io != nil {
+			_ = s.io.Close()
+		}
+		c <- true
+	}()
 	return c
 }
+
+// StopAccepting rejects new socket connections
+func (s *sckServer) StopAccepting(ctx context.Context) error {
+	atomic.StoreInt32(&s.isStopping, 1)
+	return nil
+}
+
+// Drain closes open sockets, so clients reconnect to another instance
+func (s *sckServer) Drain(ctx context.Context) error {
+	s.locker.Lock()
+	storage := s.storage
+	s.storage = make(map[int64][]AppSocket)
+	s.locker.Unlock()
+
+	for _, sockets := range storage {
+		for _, sck := range sockets {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			_ = sck.Close()
+		}
+	}
+
+	return nil
+}
@@ -7,6 +7,8 @@
 package sdkclickhouse
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"sync"
 	"time"
@@ -14,6 +16,7 @@ import (
 	_ "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 )
 
 const retryCount = 10
@@ -52,14 +55,14 @@ func (chDB *clickhouseDB) Name() string {
 	return chDB.name
 }
 
-func (chDB *clickhouseDB) InitFlags() {
+func (chDB *clickhouseDB) InitFlags(fs *flag.FlagSet) {
 	prefix := chDB.Prefix
 	if chDB.Prefix != "" {
 		prefix += "-"
 	}
 
-	flag.StringVar(&chDB.ChUri, prefix+"clickhouse-uri", "", "ClickHouse connection-string. Ex: tcp://host1:9000?username=user&password=qwerty&database=clicks")
-	flag.IntVar(&chDB.PingInterval, prefix+"clickhouse-ping-interval", 5, "ClickHouse ping check interval")
+	fs.StringVar(&chDB.ChUri, prefix+"clickhouse-uri", "", "ClickHouse connection-string. Ex: tcp://host1:9000?username=user&password=qwerty&database=clicks")
+	fs.IntVar(&chDB.PingInterval, prefix+"clickhouse-ping-interval", 5, "ClickHouse ping check interval")
 }
 
 func (chDB *clickhouseDB) isDisabled() bool {
@@ -72,12 +75,12 @@ func (chDB *clickhouseDB) Configure() error {
 	}
 
 	chDB.logger = logger.GetCurrent().GetLogger(chDB.name)
-	chDB.logger.Info("Connect to ClickHouse at ", chDB.ChUri, " ...")
+	chDB.logger.Info("Connect to ClickHouse at ", secret.RedactURI(chDB.ChUri), " ...")
 
 	var err error
 	chDB.session, err = chDB.getConnWithRetry()
 	if err != nil {
-		chDB.logger.Error("Error connect to ClickHouse at ", chDB.ChUri, ". ", err.Error())
+		chDB.logger.Error("Error connect to ClickHouse at ", secret.RedactURI(chDB.ChUri), ". ", err.Error())
 		return err
 	}
 	chDB.isRunning = true
@@ -109,6 +112,18 @@ func (chDB *clickhouseDB) Stop() <-chan bool {
 	return c
 }
 
+func (chDB *clickhouseDB) Health(ctx context.Context) error {
+	if chDB.isDisabled() {
+		return nil
+	}
+
+	if chDB.session == nil {
+		return errors.New("clickhouse is not connected")
+	}
+
+	return chDB.session.PingContext(ctx)
+}
+
 func (chDB *clickhouseDB) Get() interface{} {
 	chDB.once.Do(func() {
 		if !chDB.isRunning && !chDB.isDisabled() {
@@ -9,8 +9,12 @@
 package sdkes
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 	"github.com/olivere/elastic/v7"
 	"log"
 	"os"
@@ -62,18 +66,18 @@ func (es *es) isDisabled() bool {
 	return es.URL == ""
 }
 
-func (es *es) InitFlags() {
+func (es *es) InitFlags(fs *flag.FlagSet) {
 	prefix := es.Prefix
 	if es.Prefix != "" {
 		prefix += "-"
 	}
-	flag.StringVar(&es.Index, prefix+"es-index", "", "elastic search index")
-	flag.StringVar(&es.URL, prefix+"es-url", "", "elastic search connection-string. ex: http://localhost:9200")
-	flag.BoolVar(&es.HasSniff, prefix+"es-has-sniff", false, "elastic search sniffing mode. default: false")
-	flag.BoolVar(&es.HasHealthCheck, prefix+"es-has-health-check", false, "elastic search health-check mode. default: false")
-	flag.StringVar(&es.Username, prefix+"es-username", "", "elasticsearch username")
-	flag.StringVar(&es.Password, prefix+"es-password", "", "elasticsearch password")
-	flag.Parse()
+	fs.StringVar(&es.Index, prefix+"es-index", "", "elastic search index")
+	fs.StringVar(&es.URL, prefix+"es-url", "", "elastic search connection-string. ex: http://localhost:9200")
+	fs.BoolVar(&es.HasSniff, prefix+"es-has-sniff", false, "elastic search sniffing mode. default: false")
+	fs.BoolVar(&es.HasHealthCheck, prefix+"es-has-health-check", false, "elastic search health-check mode. default: false")
+	fs.StringVar(&es.Username, prefix+"es-username", "", "elasticsearch username")
+	fs.StringVar(&es.Password, prefix+"es-password", "", "elasticsearch password")
+	secret.MarkFlag(prefix + "es-password")
 }
 
 func (es *es) Configure() error {
@@ -81,7 +85,7 @@ func (es *es) Configure() error {
 		return nil
 	}
 	es.logger = logger.GetCurrent().GetLogger(es.name)
-	es.logger.Info("connecting to elastic search at ", es.URL, "...")
+	es.logger.Info("connecting to elastic search at ", secret.RedactURI(es.URL), "...")
 
 	var client *elastic.Client
 	var err error
@@ -118,6 +122,27 @@ func (es *es) Get() interface{} {
 	return es.client
 }
 
+func (es *es) Health(ctx context.Context) error {
+	if es.isDisabled() {
+		return nil
+	}
+
+	if es.client == nil {
+		return errors.New("elastic search is not connected")
+	}
+
+	_, code, err := es.client.Ping(es.URL).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if code >= 400 {
+		return fmt.Errorf("elastic search ping returned status %d", code)
+	}
+
+	return nil
+}
+
 func (es *es) Run() error {
 	return es.Configure()
 }
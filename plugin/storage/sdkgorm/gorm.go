@@ -7,11 +7,14 @@
 package sdkgorm
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"github.com/lequocbinh04/go-sdk/logger"
 	"github.com/lequocbinh04/go-sdk/plugin/storage/sdkgorm/gormdialects"
+	"github.com/lequocbinh04/go-sdk/util/flagset"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
 	"strings"
@@ -48,6 +51,10 @@ type gormDB struct {
 	isRunning bool
 	once      *sync.Once
 	*GormOpt
+	// options the db is connected with
+	applied GormOpt
+	// guards applied
+	mu sync.Mutex
 }
 
 func NewGormDB(name, prefix string) *gormDB {
@@ -69,31 +76,35 @@ func (gdb *gormDB) Name() string {
 	return gdb.name
 }
 
-func (gdb *gormDB) InitFlags() {
-	prefix := gdb.Prefix
-	if gdb.Prefix != "" {
+func (gdb *gormDB) InitFlags(fs *flag.FlagSet) {
+	gdb.GormOpt.initFlags(fs)
+}
+
+func (opt *GormOpt) initFlags(fs *flag.FlagSet) {
+	prefix := opt.Prefix
+	if opt.Prefix != "" {
 		prefix += "-"
 	}
 
-	flag.StringVar(&gdb.Uri, prefix+"gorm-db-uri", "", "Gorm database connection-string.")
-	flag.StringVar(&gdb.DBType, prefix+"gorm-db-type", "mysql", "Gorm database type (mysql, postgres, sqlite, mssql)")
-	flag.IntVar(&gdb.PingInterval, prefix+"gorm-db-ping-interval", 5, "Gorm database ping check interval")
-	flag.IntVar(
-		&gdb.MaxOpenConnections,
+	fs.StringVar(&opt.Uri, prefix+"gorm-db-uri", "", "Gorm database connection-string.")
+	fs.StringVar(&opt.DBType, prefix+"gorm-db-type", "mysql", "Gorm database type (mysql, postgres, sqlite, mssql)")
+	fs.IntVar(&opt.PingInterval, prefix+"gorm-db-ping-interval", 5, "Gorm database ping check interval")
+	fs.IntVar(
+		&opt.MaxOpenConnections,
 		fmt.Sprintf("%sdb-max-conn", prefix),
 		50,
 		"maximum number of open connections to the database - Default 50",
 	)
 
-	flag.IntVar(
-		&gdb.MaxIdleConnections,
+	fs.IntVar(
+		&opt.MaxIdleConnections,
 		fmt.Sprintf("%sdb-max-ide-conn", prefix),
 		15,
 		"maximum number of database connections in the idle - Default 10",
 	)
 
-	flag.IntVar(
-		&gdb.MaxConnectionIdleTime,
+	fs.IntVar(
+		&opt.MaxConnectionIdleTime,
 		fmt.Sprintf("%sdb-max-conn-ide-time", prefix),
 		3600,
 		"maximum amount of time a connection may be idle in seconds - Default 3600",
@@ -116,15 +127,18 @@ func (gdb *gormDB) Configure() error {
 		return errors.New("gorm database type is not supported")
 	}
 
-	gdb.logger.Info("Connect to Gorm DB at ", gdb.Uri, " ...")
+	gdb.logger.Info("Connect to Gorm DB at ", secret.RedactURI(gdb.Uri), " ...")
 
 	var err error
 	gdb.db, err = gdb.getDBConn(dbType)
 	if err != nil {
-		gdb.logger.Error("Error connect to gorm database at ", gdb.Uri, ". ", err.Error())
+		gdb.logger.Error("Error connect to gorm database at ", secret.RedactURI(gdb.Uri), ". ", err.Error())
 		return err
 	}
 	gdb.isRunning = true
+	gdb.mu.Lock()
+	gdb.applied = *gdb.GormOpt
+	gdb.mu.Unlock()
 
 	return nil
 }
@@ -138,21 +152,95 @@ func (gdb *gormDB) Stop() <-chan bool {
 
 	c := make(chan bool)
 	go func() {
-		c <- true
 		gdb.logger.Infoln("Stopped")
+		c <- true
 	}()
 	return c
 }
 
+// Reconfigure applies new connection pool options of fs,
+// changing database uri or type needs a restart
+func (gdb *gormDB) Reconfigure(fs *flag.FlagSet) error {
+	opt := GormOpt{Prefix: gdb.Prefix}
+	next := flag.NewFlagSet(gdb.name, flag.ContinueOnError)
+	opt.initFlags(next)
+	if err := flagset.Copy(next, fs); err != nil {
+		return err
+	}
+
+	if gdb.db == nil {
+		if opt.Uri != "" {
+			return errors.New("gorm database cannot be enabled without restart")
+		}
+		return nil
+	}
+
+	gdb.mu.Lock()
+	applied := gdb.applied
+	gdb.mu.Unlock()
+
+	if opt.Uri != applied.Uri || opt.DBType != applied.DBType {
+		return errors.New("gorm database uri and type cannot be changed without restart")
+	}
+
+	if opt.MaxOpenConnections < 0 || opt.MaxIdleConnections < 0 || opt.MaxConnectionIdleTime < 0 {
+		return errors.New("gorm database connection pool options must not be negative")
+	}
+
+	db, err := gdb.db.DB()
+	if err != nil {
+		return err
+	}
+
+	db.SetMaxOpenConns(opt.MaxOpenConnections)
+	db.SetMaxIdleConns(opt.MaxIdleConnections)
+	db.SetConnMaxIdleTime(time.Second * time.Duration(opt.MaxConnectionIdleTime))
+
+	gdb.mu.Lock()
+	gdb.applied = opt
+	gdb.mu.Unlock()
+
+	gdb.logger.Infof("Connection pool reloaded: max open %d, max idle %d, max idle time %ds",
+		opt.MaxOpenConnections, opt.MaxIdleConnections, opt.MaxConnectionIdleTime)
+
+	return nil
+}
+
+func (gdb *gormDB) Health(ctx context.Context) error {
+	if gdb.isDisabled() {
+		return nil
+	}
+
+	if gdb.db == nil {
+		return errors.New("gorm database is not connected")
+	}
+
+	db, err := gdb.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
+
 func (gdb *gormDB) Get() interface{} {
+	if gdb.db == nil {
+		return nil
+	}
+
 	if gdb.logger.GetLevel() == "debug" || gdb.logger.GetLevel() == "trace" {
 		return gdb.db.Session(&gorm.Session{NewDB: true}).Debug()
 	}
 	newSessionDB := gdb.db.Session(&gorm.Session{NewDB: true, Logger: gdb.db.Logger.LogMode(glogger.Silent)})
 	if db, err := newSessionDB.DB(); err == nil {
-		db.SetMaxOpenConns(gdb.MaxOpenConnections)
-		db.SetMaxIdleConns(gdb.MaxIdleConnections)
-		db.SetConnMaxIdleTime(time.Second * time.Duration(gdb.MaxConnectionIdleTime))
+		// options of the last reload
+		gdb.mu.Lock()
+		applied := gdb.applied
+		gdb.mu.Unlock()
+
+		db.SetMaxOpenConns(applied.MaxOpenConnections)
+		db.SetMaxIdleConns(applied.MaxIdleConnections)
+		db.SetConnMaxIdleTime(time.Second * time.Duration(applied.MaxConnectionIdleTime))
 	}
 	return newSessionDB
 }
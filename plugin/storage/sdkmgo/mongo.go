@@ -8,8 +8,10 @@ package sdkmgo
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/secret"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"math"
@@ -62,14 +64,14 @@ func (mgDB *mongoDB) Name() string {
 	return mgDB.name
 }
 
-func (mgDB *mongoDB) InitFlags() {
+func (mgDB *mongoDB) InitFlags(fs *flag.FlagSet) {
 	prefix := mgDB.Prefix
 	if mgDB.Prefix != "" {
 		prefix += "-"
 	}
 
-	flag.StringVar(&mgDB.MgoUri, prefix+"mgo-uri", "", "MongoDB connection-string. Ex: mongodb://...")
-	flag.IntVar(&mgDB.PingInterval, prefix+"mgo-ping-interval", 5, "MongoDB ping check interval")
+	fs.StringVar(&mgDB.MgoUri, prefix+"mgo-uri", "", "MongoDB connection-string. Ex: mongodb://...")
+	fs.IntVar(&mgDB.PingInterval, prefix+"mgo-ping-interval", 5, "MongoDB ping check interval")
 }
 
 func (mgDB *mongoDB) isDisabled() bool {
@@ -82,12 +84,12 @@ func (mgDB *mongoDB) Configure() error {
 	}
 
 	mgDB.logger = logger.GetCurrent().GetLogger(mgDB.name)
-	mgDB.logger.Info("Connect to Mongodb at ", mgDB.MgoUri, " ...")
+	mgDB.logger.Info("Connect to Mongodb at ", secret.RedactURI(mgDB.MgoUri), " ...")
 
 	var err error
 	mgDB.session, err = mgDB.getConnWithRetry(retryCount)
 	if err != nil {
-		mgDB.logger.Error("Error connect to mongodb at ", mgDB.MgoUri, ". ", err.Error())
+		mgDB.logger.Error("Error connect to mongodb at ", secret.RedactURI(mgDB.MgoUri), ". ", err.Error())
 		return err
 	}
 	mgDB.isRunning = true
@@ -119,6 +121,18 @@ func (mgDB *mongoDB) Stop() <-chan bool {
 	return c
 }
 
+func (mgDB *mongoDB) Health(ctx context.Context) error {
+	if mgDB.isDisabled() {
+		return nil
+	}
+
+	if mgDB.session == nil {
+		return errors.New("mongodb is not connected")
+	}
+
+	return mgDB.session.Ping(ctx, nil)
+}
+
 func (mgDB *mongoDB) Get() interface{} {
 	mgDB.once.Do(func() {
 		if !mgDB.isRunning && !mgDB.isDisabled() {
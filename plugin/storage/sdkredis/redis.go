@@ -27,9 +27,15 @@ package sdkredis
 // 		Distributed Locks.
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"github.com/go-redis/redis/v7"
 	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/lequocbinh04/go-sdk/util/flagset"
+	"github.com/lequocbinh04/go-sdk/util/secret"
+	"sync"
+	"time"
 )
 
 var (
@@ -37,6 +43,8 @@ var (
 	DefaultRedisDB        = getDefaultRedisDB()
 	defaultRedisMaxActive = 0 // 0 is unlimited max active connection
 	defaultRedisMaxIdle   = 10
+	// clients replaced on reload are closed after this delay, so calls in flight can finish
+	retireGracePeriod = time.Minute
 )
 
 type RedisDBOpt struct {
@@ -51,6 +59,12 @@ type redisDB struct {
 	client *redis.Client
 	logger logger.Logger
 	*RedisDBOpt
+	// options the client is connected with
+	applied RedisDBOpt
+	// clients replaced on reload with timers closing them
+	retired map[*redis.Client]*time.Timer
+	// guards client, applied and retired
+	mu sync.Mutex
 }
 
 func getDefaultRedisDB() *redisDB {
@@ -76,15 +90,19 @@ func (r *redisDB) isDisabled() bool {
 	return r.RedisUri == ""
 }
 
-func (r *redisDB) InitFlags() {
-	prefix := r.Prefix
-	if r.Prefix != "" {
+func (r *redisDB) InitFlags(fs *flag.FlagSet) {
+	r.RedisDBOpt.initFlags(fs)
+}
+
+func (opt *RedisDBOpt) initFlags(fs *flag.FlagSet) {
+	prefix := opt.Prefix
+	if opt.Prefix != "" {
 		prefix += "-"
 	}
 
-	flag.StringVar(&r.RedisUri, prefix+"go-redis-uri", "", "(For go-redis) Redis connection-string. Ex: redis://localhost/0")
-	flag.IntVar(&r.MaxActive, prefix+"go-redis-pool-max-active", defaultRedisMaxActive, "(For go-redis) Override redis pool MaxActive")
-	flag.IntVar(&r.MaxIde, prefix+"go-redis-pool-max-idle", defaultRedisMaxIdle, "(For go-redis) Override redis pool MaxIdle")
+	fs.StringVar(&opt.RedisUri, prefix+"go-redis-uri", "", "(For go-redis) Redis connection-string. Ex: redis://localhost/0")
+	fs.IntVar(&opt.MaxActive, prefix+"go-redis-pool-max-active", defaultRedisMaxActive, "(For go-redis) Override redis pool MaxActive")
+	fs.IntVar(&opt.MaxIde, prefix+"go-redis-pool-max-idle", defaultRedisMaxIdle, "(For go-redis) Override redis pool MaxIdle")
 }
 
 func (r *redisDB) Configure() error {
@@ -93,49 +111,157 @@ func (r *redisDB) Configure() error {
 	}
 
 	r.logger = logger.GetCurrent().GetLogger(r.name)
-	r.logger.Info("Connecting to Redis at ", r.RedisUri, "...")
+	r.logger.Info("Connecting to Redis at ", secret.RedactURI(r.RedisUri), "...")
+
+	client, err := r.connect(*r.RedisDBOpt)
+	if err != nil {
+		return err
+	}
+
+	// Connect successfully, assign client to goRedisDB
+	r.mu.Lock()
+	r.client = client
+	r.applied = *r.RedisDBOpt
+	r.mu.Unlock()
+	return nil
+}
 
-	opt, err := redis.ParseURL(r.RedisUri)
+func (r *redisDB) connect(dbOpt RedisDBOpt) (*redis.Client, error) {
+	opt, err := redis.ParseURL(dbOpt.RedisUri)
 
 	if err != nil {
 		r.logger.Error("Cannot parse Redis ", err.Error())
-		return err
+		return nil, err
 	}
 
-	opt.PoolSize = r.MaxActive
-	opt.MinIdleConns = r.MaxIde
+	opt.PoolSize = dbOpt.MaxActive
+	opt.MinIdleConns = dbOpt.MaxIde
 
 	client := redis.NewClient(opt)
 
 	// Ping to test Redis connection
 	if err := client.Ping().Err(); err != nil {
 		r.logger.Error("Cannot connect Redis. ", err.Error())
+		_ = client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Reconfigure connects with new uri and pool sizes of fs then replaces the client.
+// Callers should get the client from the component instead of keeping it
+func (r *redisDB) Reconfigure(fs *flag.FlagSet) error {
+	opt := RedisDBOpt{Prefix: r.Prefix}
+	next := flag.NewFlagSet(r.name, flag.ContinueOnError)
+	opt.initFlags(next)
+	if err := flagset.Copy(next, fs); err != nil {
 		return err
 	}
 
-	// Connect successfully, assign client to goRedisDB
-	r.client = client
+	r.mu.Lock()
+	current, applied := r.client, r.applied
+	r.mu.Unlock()
+
+	if current == nil {
+		if opt.RedisUri != "" {
+			return errors.New("redis cannot be enabled without restart")
+		}
+		return nil
+	}
+
+	if opt == applied {
+		return nil
+	}
+
+	if opt.RedisUri == "" {
+		return errors.New("redis cannot be disabled without restart")
+	}
+
+	client, err := r.connect(opt)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.client, r.applied = client, opt
+	r.mu.Unlock()
+	r.retire(current)
+
+	r.logger.Infof("Reconnected with pool max active %d, max idle %d", opt.MaxActive, opt.MaxIde)
 	return nil
 }
 
+// retire closes a client replaced on reload after retireGracePeriod
+func (r *redisDB) retire(client *redis.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.retired == nil {
+		r.retired = map[*redis.Client]*time.Timer{}
+	}
+
+	r.retired[client] = time.AfterFunc(retireGracePeriod, func() {
+		r.mu.Lock()
+		delete(r.retired, client)
+		r.mu.Unlock()
+
+		r.close(client)
+	})
+}
+
+func (r *redisDB) close(client *redis.Client) {
+	if err := client.Close(); err != nil {
+		r.logger.Info("cannot close ", r.name)
+	}
+}
+
 func (r *redisDB) Name() string {
 	return r.name
 }
 
 func (r *redisDB) Get() interface{} {
+	return r.getClient()
+}
+
+func (r *redisDB) getClient() *redis.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.client
 }
 
+func (r *redisDB) Health(ctx context.Context) error {
+	if r.isDisabled() {
+		return nil
+	}
+
+	client := r.getClient()
+	if client == nil {
+		return errors.New("redis is not connected")
+	}
+
+	return client.WithContext(ctx).Ping().Err()
+}
+
 func (r *redisDB) Run() error {
 	return r.Configure()
 }
 
 func (r *redisDB) Stop() <-chan bool {
-	if r.client != nil {
-		if err := r.client.Close(); err != nil {
-			r.logger.Info("cannot close ", r.name)
+	r.mu.Lock()
+	for client, timer := range r.retired {
+		// a timer already fired closes its client itself
+		if timer.Stop() {
+			r.close(client)
 		}
 	}
+	r.retired = nil
+	client := r.client
+	r.mu.Unlock()
+
+	if client != nil {
+		r.close(client)
+	}
 
 	c := make(chan bool)
 	go func() { c <- true }()
@@ -0,0 +1,200 @@
+// Package tracing sets the global OpenTelemetry tracer provider, exporting spans with OTLP
+// over HTTP or gRPC, or to stdout for local debugging. Trace context is propagated with
+// W3C traceparent and baggage headers. It replaces plugin/jaeger.
+//
+//	service := goservice.New(
+//		goservice.WithName("orders"),
+//		goservice.WithInitRunnable(tracing.New("tracing", "orders")),
+//	)
+//
+// Run it with -tracing-exporter=otlp-grpc -tracing-endpoint=otel-collector:4317
+package tracing
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone     = "none"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterStdout   = "stdout"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+var ErrInvalidExporter = errors.New("invalid tracing exporter")
+
+type tracingConfig struct {
+	exporter   string
+	endpoint   string
+	insecure   bool
+	sampleRate float64
+}
+
+type tracing struct {
+	prefix      string
+	serviceName string
+	logger      logger.Logger
+	cfg         tracingConfig
+	provider    *sdktrace.TracerProvider
+	// written by the stdout exporter
+	out io.Writer
+}
+
+// New creates the tracer provider component, spans are tagged with serviceName
+func New(prefix, serviceName string) *tracing {
+	return &tracing{
+		prefix:      prefix,
+		serviceName: serviceName,
+		out:         os.Stdout,
+	}
+}
+
+func (t *tracing) GetPrefix() string {
+	return t.prefix
+}
+
+// Get returns the trace.TracerProvider, nil if no exporter is set
+func (t *tracing) Get() interface{} {
+	if t.provider == nil {
+		return nil
+	}
+	return trace.TracerProvider(t.provider)
+}
+
+func (t *tracing) Name() string {
+	return t.prefix
+}
+
+func (t *tracing) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&t.cfg.exporter, fmt.Sprintf("%s-%s", t.prefix, "exporter"), ExporterNone,
+		"Exporter of spans: none | otlp-http | otlp-grpc | stdout")
+	fs.StringVar(&t.cfg.endpoint, fmt.Sprintf("%s-%s", t.prefix, "endpoint"), "",
+		"host:port of the OTLP receiver. Default: OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318 (http), localhost:4317 (grpc)")
+	fs.BoolVar(&t.cfg.insecure, fmt.Sprintf("%s-%s", t.prefix, "insecure"), false,
+		"Export to the OTLP receiver without TLS")
+	fs.Float64Var(&t.cfg.sampleRate, fmt.Sprintf("%s-%s", t.prefix, "sample-rate"), 1.0,
+		"Ratio of traces started by the service to sample: 0.0 -> 1.0, sampled parents are always followed")
+}
+
+func (t *tracing) Configure() error {
+	t.logger = logger.GetCurrent().GetLogger(t.prefix)
+
+	switch t.cfg.exporter {
+	case ExporterNone, ExporterOTLPHTTP, ExporterOTLPGRPC, ExporterStdout:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidExporter, t.cfg.exporter)
+	}
+
+	return nil
+}
+
+func (t *tracing) Run() error {
+	if err := t.Configure(); err != nil {
+		return err
+	}
+
+	// extract incoming trace context even if this service exports nothing
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if t.cfg.exporter == ExporterNone {
+		return nil
+	}
+
+	exporter, err := t.newExporter(context.Background())
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(t.serviceName),
+	))
+	if err != nil {
+		return err
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(newSampler(t.cfg.sampleRate)),
+	)
+	otel.SetTracerProvider(t.provider)
+
+	t.logger.Infof("exporting traces with %s", t.cfg.exporter)
+
+	return nil
+}
+
+func (t *tracing) newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch t.cfg.exporter {
+	case ExporterOTLPHTTP:
+		var opts []otlptracehttp.Option
+		if t.cfg.endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(t.cfg.endpoint))
+		}
+		if t.cfg.insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptrace.New(ctx, otlptracehttp.NewClient(opts...))
+	case ExporterOTLPGRPC:
+		var opts []otlptracegrpc.Option
+		if t.cfg.endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(t.cfg.endpoint))
+		}
+		if t.cfg.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+	}
+
+	return stdouttrace.New(stdouttrace.WithWriter(t.out), stdouttrace.WithPrettyPrint())
+}
+
+func newSampler(rate float64) sdktrace.Sampler {
+	if rate >= 1 {
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(rate))
+}
+
+// Stop flushes pending spans before the exporter shuts down
+func (t *tracing) Stop() <-chan bool {
+	c := make(chan bool)
+
+	go func() {
+		if t.provider != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if err := t.provider.Shutdown(ctx); err != nil {
+				t.logger.Errorln("cannot flush spans:", err)
+			}
+		}
+		c <- true
+	}()
+
+	return c
+}
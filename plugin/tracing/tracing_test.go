@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lequocbinh04/go-sdk/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracing(t *testing.T, args ...string) *tracing {
+	logger.InitServLogger(false)
+
+	tr := New("tracing", "orders")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	tr.InitFlags(fs)
+	require.NoError(t, fs.Parse(args))
+
+	return tr
+}
+
+func TestConfigureInvalidExporter(t *testing.T) {
+	tr := newTestTracing(t, "-tracing-exporter=zipkin")
+	assert.ErrorIs(t, tr.Configure(), ErrInvalidExporter)
+}
+
+func TestNoneExporter(t *testing.T) {
+	tr := newTestTracing(t)
+	require.NoError(t, tr.Run())
+
+	assert.Nil(t, tr.Get())
+	assert.True(t, <-tr.Stop())
+}
+
+func TestStdoutExporterPropagation(t *testing.T) {
+	tr := newTestTracing(t, "-tracing-exporter=stdout")
+	out := &bytes.Buffer{}
+	tr.out = out
+	require.NoError(t, tr.Run())
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+
+	assert.NotNil(t, tr.Get())
+
+	var spanCtx trace.SpanContext
+	handler := otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spanCtx = trace.SpanContextFromContext(r.Context())
+	}), "orders")
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanCtx.TraceID().String())
+	assert.True(t, spanCtx.IsSampled())
+
+	// spans are flushed on stop
+	assert.True(t, <-tr.Stop())
+	assert.Contains(t, out.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, out.String(), `"service.name"`)
+}
+
+func TestSampler(t *testing.T) {
+	assert.Contains(t, newSampler(1).Description(), "AlwaysOnSampler")
+	assert.Contains(t, newSampler(0.25).Description(), "TraceIDRatioBased{0.25}")
+}
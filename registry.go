@@ -0,0 +1,136 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lequocbinh04/go-sdk/httpserver"
+	"github.com/lequocbinh04/go-sdk/plugin/registry"
+)
+
+const registryTimeout = 5 * time.Second
+
+// WithRegistry registers the service on the init component with prefix implementing
+// registry.Registry (Ex: plugin/registry/consul) when the HTTP server listens, before
+// OnReady hooks, with the address of the server and its /readyz health check.
+// It is deregistered first when it stops
+func WithRegistry(prefix string) Option {
+	return func(s *service) {
+		s.registryPrefix = prefix
+		s.hasRegistry = true
+	}
+}
+
+// activeRegistry registers the service and returns the function deregistering it
+func (sv *service) activeRegistry() (func(), error) {
+	if !sv.hasRegistry {
+		return func() {}, nil
+	}
+
+	reg, err := Get[registry.Registry](sv, sv.registryPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := sv.httpServer.(interface{ IsRunning() bool }); !ok || !r.IsRunning() {
+		sv.logger.Infoln("http server is not running, skip registering the service")
+		return func() {}, nil
+	}
+
+	instance, err := sv.registryInstance(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+
+	if err := reg.Register(ctx, instance); err != nil {
+		return nil, fmt.Errorf("registering service: %w", err)
+	}
+	sv.isRegister = true
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+		defer cancel()
+
+		if err := reg.Deregister(ctx, instance.ID); err != nil {
+			sv.logger.Errorln("cannot deregister service:", err)
+		}
+		sv.isRegister = false
+	}, nil
+}
+
+// registryInstance describes the service with the address of its HTTP server
+func (sv *service) registryInstance(reg registry.Registry) (*registry.Instance, error) {
+	host, portStr, err := net.SplitHostPort(sv.httpServer.URI())
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if a, ok := reg.(interface{ AdvertiseAddr() string }); ok && a.AdvertiseAddr() != "" {
+		host = a.AdvertiseAddr()
+	} else if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if host, err = privateIP(); err != nil {
+			return nil, err
+		}
+	}
+
+	scheme := "http"
+	if gs, ok := sv.httpServer.(httpserver.GinService); ok {
+		scheme = gs.Scheme()
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &registry.Instance{
+		ID:             fmt.Sprintf("%s-%s-%d", sv.name, hostname, port),
+		Name:           sv.name,
+		Version:        sv.version,
+		Address:        host,
+		Port:           port,
+		Meta:           map[string]string{"env": sv.env},
+		HealthCheckURL: fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, portStr), readinessPath),
+	}, nil
+}
+
+// privateIP returns the first private IPv4 address of the host, or a loopback one
+func privateIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+
+	var loopback string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil {
+			continue
+		}
+
+		if ipNet.IP.IsPrivate() {
+			return ipNet.IP.String(), nil
+		}
+
+		if ipNet.IP.IsLoopback() && loopback == "" {
+			loopback = ipNet.IP.String()
+		}
+	}
+
+	if loopback == "" {
+		return "", fmt.Errorf("no IPv4 address to register")
+	}
+
+	return loopback, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/lequocbinh04/go-sdk/logger"
+)
+
+// Reloadable is an optional interface for Runnable components which can apply
+// new flag values while running. When the service receives SIGHUP, it loads the
+// env file, parses flags again into a copy of the flag set then calls Reconfigure
+// of every Reloadable component with it. Flags bound by InitFlags keep their values,
+// components read new ones into a copy of their options, see util/flagset.
+// If Reconfigure returns an error, the component must keep its old config.
+type Reloadable interface {
+	Reconfigure(fs *flag.FlagSet) error
+}
+
+// loadEnvFile sets env variables from ENV_FILE (default: .env).
+// Variables of the process environment always win over the file,
+// so the file can be loaded again on reload.
+func (sv *service) loadEnvFile() error {
+	if sv.processEnv == nil {
+		sv.processEnv = map[string]bool{}
+		for _, kv := range os.Environ() {
+			sv.processEnv[strings.SplitN(kv, "=", 2)[0]] = true
+		}
+	}
+
+	envFile := os.Getenv("ENV_FILE")
+	if envFile == "" {
+		envFile = ".env"
+	}
+
+	envMap := map[string]string{}
+
+	_, err := os.Stat(envFile)
+	if err == nil {
+		if envMap, err = godotenv.Read(envFile); err != nil {
+			return fmt.Errorf("loading env(%s): %s", envFile, err.Error())
+		}
+	} else if envFile != ".env" {
+		return fmt.Errorf("loading env(%s): %s", envFile, err.Error())
+	}
+
+	// Variables removed from the file since the last load
+	for key := range sv.envFileKeys {
+		if _, ok := envMap[key]; !ok {
+			_ = os.Unsetenv(key)
+		}
+	}
+
+	sv.envFileKeys = map[string]bool{}
+	for key, value := range envMap {
+		if sv.processEnv[key] {
+			continue
+		}
+
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+		sv.envFileKeys[key] = true
+	}
+
+	return nil
+}
+
+// reload re-reads config and applies it to Reloadable components.
+// Failures are logged and the old config is kept.
+func (sv *service) reload() {
+	sv.logger.Infoln("Reloading config...")
+
+	if err := sv.loadEnvFile(); err != nil {
+		sv.logger.Errorln("Reload failed, keep old config:", err)
+		return
+	}
+
+	next := sv.cmdLine.clone()
+	if err := next.Reparse(); err != nil {
+		sv.logger.Errorln("Reload failed, keep old config:", err)
+		return
+	}
+
+	for _, r := range sv.reloadables() {
+		if err := r.Reconfigure(next.FlagSet); err != nil {
+			sv.logger.Errorf("Cannot reload %s, keep old config: %s", r.Name(), err.Error())
+		}
+	}
+
+	sv.logger.Infoln("Config reloaded")
+}
+
+type reloadableRunnable interface {
+	Reloadable
+	Name() string
+}
+
+// reloadables returns Reloadable components: the logger first,
+// then init components in dependency order and the others
+func (sv *service) reloadables() []reloadableRunnable {
+	var components []reloadableRunnable
+
+	if r, ok := logger.GetCurrent().(reloadableRunnable); ok {
+		components = append(components, r)
+	}
+
+	prefixes, err := sv.sortedInitPrefixes()
+	if err != nil {
+		prefixes = sv.initOrder
+	}
+
+	for _, pre := range prefixes {
+		if r, ok := unwrapComponent(sv.initServices[pre]).(reloadableRunnable); ok {
+			components = append(components, r)
+		}
+	}
+
+	for _, subService := range sv.subServices {
+		if r, ok := unwrapComponent(subService).(reloadableRunnable); ok {
+			components = append(components, r)
+		}
+	}
+
+	return components
+}
@@ -0,0 +1,48 @@
+package goservice
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reloadableComponent struct {
+	testComponent
+	uri string
+	// uri of the flag set passed to Reconfigure
+	reloaded string
+}
+
+func (c *reloadableComponent) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.uri, "reload-uri", "default", "")
+}
+
+func (c *reloadableComponent) Reconfigure(fs *flag.FlagSet) error {
+	c.reloaded = fs.Lookup("reload-uri").Value.String()
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	c := &reloadableComponent{testComponent: testComponent{prefix: "reload"}}
+	sv := newTestService(WithInitRunnable(c))
+	sv.cmdLine = newFlagSet("test", flag.NewFlagSet("test", flag.ContinueOnError))
+	sv.cmdLine.String("explicit", "", "")
+	c.InitFlags(sv.cmdLine.FlagSet)
+
+	assert.NoError(t, sv.cmdLine.Parse([]string{"-explicit=arg"}))
+	assert.Equal(t, "default", c.uri)
+
+	t.Setenv("RELOAD_URI", "redis://new")
+	t.Setenv("EXPLICIT", "env")
+	sv.reload()
+
+	// flags of the running component are not changed
+	assert.Equal(t, "default", c.uri)
+	assert.Equal(t, "redis://new", c.reloaded)
+	assert.Equal(t, "arg", sv.cmdLine.Lookup("explicit").Value.String())
+
+	next := sv.cmdLine.clone()
+	assert.NoError(t, next.Reparse())
+	assert.Equal(t, "arg", next.Lookup("explicit").Value.String(), "command line arguments win over env")
+}
@@ -14,6 +14,16 @@ type AppError struct {
 	Log        string `json:"log"`
 	Key        string `json:"error_key"`
 	TraceID    string `json:"trace_id,omitempty"`
+	// invalid fields of the request, see ErrValidation
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a field of the request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// rule the field failed. Ex: required, email
+	Rule string `json:"rule,omitempty"`
 }
 
 func NewErrorResponse(statusCode int, root error, msg, log, key string) *AppError {
@@ -81,6 +91,15 @@ func ErrInvalidRequest(err error) *AppError {
 	return NewErrorResponse(http.StatusBadRequest, err, "invalid request", err.Error(), "ErrInvalidRequest")
 }
 
+// ErrValidation is an invalid request error with the invalid fields
+func ErrValidation(fields ...FieldError) *AppError {
+	appErr := NewErrorResponse(http.StatusBadRequest, errors.New("validation failed"),
+		"invalid request", "validation failed", "ErrValidation")
+	appErr.Errors = fields
+
+	return appErr
+}
+
 func ErrInternal(err error) *AppError {
 	return NewFullErrorResponse(http.StatusInternalServerError, err,
 		"something went wrong in the server", err.Error(), "ErrInternal")
@@ -0,0 +1,36 @@
+package sdkcm
+
+import "net/http"
+
+// ContentTypeProblem is the media type of Problem
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an AppError in the format of RFC 7807 problem details,
+// error_key, trace_id, log and errors are extension members
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	ErrorKey string       `json:"error_key,omitempty"`
+	TraceID  string       `json:"trace_id,omitempty"`
+	Log      string       `json:"log,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// Problem returns the problem details of the error which occurred on instance (Ex: the request path).
+// Errors are told apart by error_key, so the type is about:blank and the title is the status text
+func (e *AppError) Problem(instance string) *Problem {
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(e.StatusCode),
+		Status:   e.StatusCode,
+		Detail:   e.Message,
+		Instance: instance,
+		ErrorKey: e.Key,
+		TraceID:  e.TraceID,
+		Log:      e.Log,
+		Errors:   e.Errors,
+	}
+}
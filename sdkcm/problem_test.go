@@ -0,0 +1,39 @@
+package sdkcm
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppErrorProblem(t *testing.T) {
+	appErr := ErrValidation(FieldError{Field: "email", Message: "email is invalid", Rule: "email"})
+	appErr.TraceID = "req-1"
+
+	b, err := json.Marshal(appErr.Problem("/users"))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid request",
+		"instance": "/users",
+		"error_key": "ErrValidation",
+		"trace_id": "req-1",
+		"log": "validation failed",
+		"errors": [{"field": "email", "message": "email is invalid", "rule": "email"}]
+	}`, string(b))
+}
+
+func TestAppErrorProblemWithoutFields(t *testing.T) {
+	p := ErrNoPermission(nil).Problem("")
+
+	assert.Equal(t, http.StatusForbidden, p.Status)
+	assert.Equal(t, "Forbidden", p.Title)
+	assert.Equal(t, "ErrNoPermission", p.ErrorKey)
+	assert.Empty(t, p.Errors)
+}
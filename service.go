@@ -5,14 +5,15 @@ package goservice
 import (
 	"flag"
 	"fmt"
-	"github.com/joho/godotenv"
 	"github.com/lequocbinh04/go-sdk/httpserver"
 	"github.com/lequocbinh04/go-sdk/logger"
 	"log"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
 const (
@@ -27,15 +28,47 @@ type service struct {
 	version      string
 	sentryDsn    string
 	env          string
+	configFile   string
+	outEnvFormat string
 	opts         []Option
 	subServices  []Runnable
 	initServices map[string]PrefixRunnable
+	initOrder    []string
+	dependencies map[string][]string
+	requirements []Requirement
 	isRegister   bool
 	logger       logger.Logger
 	httpServer   HttpServer
 	signalChan   chan os.Signal
 	cmdLine      *AppFlagSet
 	stopFunc     func()
+	ready        int32
+	// env variables set before loading env file and keys loaded from it
+	processEnv  map[string]bool
+	envFileKeys map[string]bool
+	// expose the effective config on /debug/config
+	debugConfigEnabled bool
+	// command line arguments parsed by New, see WithArgs
+	args []string
+	// init components replacing the ones with the same prefix, see WithOverride
+	overrides map[string]PrefixRunnable
+	// prefixes of init components whose flags are registered
+	flagsInitialized map[string]bool
+	stopOnce         sync.Once
+	stopped          chan struct{}
+	stopErr          error
+	shutdownTimeout  time.Duration
+	// lifecycle hooks, see WithOnStart
+	onStart []Hook
+	onReady []Hook
+	onStop  []Hook
+	// prefix of the registry component, see WithRegistry
+	registryPrefix string
+	hasRegistry    bool
+	// lifecycle states of components by name, see ComponentStatus
+	statesMu sync.Mutex
+	states   map[string]ComponentStatus
+	admin    *adminServer
 }
 
 func New(opts ...Option) Service {
@@ -44,6 +77,11 @@ func New(opts ...Option) Service {
 		signalChan:   make(chan os.Signal, 1),
 		subServices:  []Runnable{},
 		initServices: map[string]PrefixRunnable{},
+		dependencies: map[string][]string{},
+		overrides:    map[string]PrefixRunnable{},
+		stopped:      make(chan struct{}),
+
+		flagsInitialized: map[string]bool{},
 	}
 
 	// init default logger
@@ -60,11 +98,14 @@ func New(opts ...Option) Service {
 
 	//// Http server
 	httpServer := httpserver.New(sv.name, sv.sentryDsn)
+	httpServer.AddSystemHandler(sv.healthHandler)
+	httpServer.AddSystemHandler(sv.configHandler)
 	sv.httpServer = httpServer
 
 	sv.subServices = append(sv.subServices, httpServer)
 
-	sv.initFlags()
+	sv.admin = newAdminServer(sv)
+	sv.subServices = append(sv.subServices, sv.admin)
 
 	if sv.name == "" {
 		if len(os.Args) >= 2 {
@@ -72,10 +113,13 @@ func New(opts ...Option) Service {
 		}
 	}
 
+	// Each service has its own flag set, so services don't share flags
+	sv.cmdLine = newFlagSet(sv.name, flag.NewFlagSet(os.Args[0], flag.ExitOnError))
+	sv.initFlags()
+
 	loggerRunnable := logger.GetCurrent().(Runnable)
-	loggerRunnable.InitFlags()
+	loggerRunnable.InitFlags(sv.cmdLine.FlagSet)
 
-	sv.cmdLine = newFlagSet(sv.name, flag.CommandLine)
 	sv.parseFlags()
 
 	_ = loggerRunnable.Configure()
@@ -107,19 +151,48 @@ func (sv *service) Version() string {
 }
 
 func (sv *service) Init() error {
-	for _, dbSv := range sv.initServices {
-		if err := dbSv.Run(); err != nil {
+	if err := sv.checkPrefixRequirements(); err != nil {
+		return err
+	}
+
+	prefixes, err := sv.sortedInitPrefixes()
+	if err != nil {
+		return err
+	}
+
+	for _, pre := range prefixes {
+		if err := sv.runComponent(pre, sv.initServices[pre]); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return sv.checkRequirements()
 }
 
 func (sv *service) InitPrefix(prefix ...string) error {
+	requested := make(map[string]bool, len(prefix))
 	for _, pre := range prefix {
-		sv.initServices[pre].InitFlags()
-		if err := sv.initServices[pre].Run(); err != nil {
+		if _, ok := sv.initServices[pre]; !ok {
+			return fmt.Errorf("component %q is not registered", pre)
+		}
+		requested[pre] = true
+	}
+
+	prefixes, err := sv.sortedInitPrefixes()
+	if err != nil {
+		return err
+	}
+
+	for _, pre := range prefixes {
+		if !requested[pre] {
+			continue
+		}
+
+		if err := sv.initComponentFlags(pre); err != nil {
+			return err
+		}
+
+		if err := sv.runComponent(pre, sv.initServices[pre]); err != nil {
 			return err
 		}
 	}
@@ -132,15 +205,45 @@ func (sv *service) IsRegistered() bool {
 
 func (sv *service) Start(exitCallback func()) error {
 	signal.Notify(sv.signalChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sv.signalChan)
+
+	if err := sv.runHooks("OnStart", sv.onStart); err != nil {
+		sv.logger.Errorln(err)
+		_ = sv.shutdown()
+		return err
+	}
+
 	c := sv.run()
-	//s.stopFunc = s.activeRegistry()
+	if err := sv.waitStarted(c); err != nil {
+		sv.logger.Errorln(err)
+		_ = sv.shutdown()
+		return err
+	}
+	sv.warnNoHealthEndpoints()
+
+	// the registry sends traffic once /readyz passes, after OnReady hooks
+	stopFunc, err := sv.activeRegistry()
+	if err != nil {
+		sv.logger.Errorln(err)
+		_ = sv.shutdown()
+		return err
+	}
+	sv.stopFunc = stopFunc
+
+	if err := sv.runHooks("OnReady", sv.onReady); err != nil {
+		sv.logger.Errorln(err)
+		_ = sv.shutdown()
+		return err
+	}
+
+	sv.setReady(true)
 
 	for {
 		select {
 		case err := <-c:
 			if err != nil {
 				sv.logger.Error(err.Error())
-				sv.Stop()
+				_ = sv.shutdown()
 				return err
 			}
 
@@ -148,28 +251,54 @@ func (sv *service) Start(exitCallback func()) error {
 			sv.logger.Infoln(sig)
 			switch sig {
 			case syscall.SIGHUP:
-				return nil
+				sv.reload()
 			default:
-				sv.Stop()
+				err := sv.shutdown()
 				if exitCallback != nil {
 					exitCallback()
 				}
-				return nil
+				return err
 			}
+
+		case <-sv.stopped:
+			return sv.stopErr
 		}
 	}
 }
 
 func (sv *service) initFlags() {
-	flag.StringVar(&sv.env, "app-env", DevEnv, "Env for service. Ex: dev | stg | prd")
+	fs := sv.cmdLine.FlagSet
+
+	fs.StringVar(&sv.env, appEnvFlag, DevEnv, "Env for service. Ex: dev | stg | prd")
+	fs.StringVar(&sv.configFile, configFileFlag, "", "Config file (yaml, json or toml). "+
+		"Values of its env-specific overlay (Ex: config.prd.yaml) are applied on top")
+	fs.StringVar(&sv.outEnvFormat, outEnvFormatFlag, "env", "Format of OutEnv: env | yaml")
+	fs.BoolVar(&sv.debugConfigEnabled, debugConfigFlag, false,
+		"Expose the effective config with masked secrets on /debug/config of the http server")
+	fs.DurationVar(&sv.shutdownTimeout, shutdownTimeoutFlag, defaultShutdownTimeout,
+		"Max duration to stop accepting connections, drain in-flight requests and stop components")
 
 	for _, subService := range sv.subServices {
-		subService.InitFlags()
+		subService.InitFlags(fs)
+	}
+
+	for _, pre := range sv.initOrder {
+		sv.initServices[pre].InitFlags(fs)
+		sv.flagsInitialized[pre] = true
 	}
+}
 
-	for _, dbService := range sv.initServices {
-		dbService.InitFlags()
+// initComponentFlags registers flags of an init component added after New,
+// then sets them from env variables and config files
+func (sv *service) initComponentFlags(prefix string) error {
+	if sv.flagsInitialized[prefix] {
+		return nil
 	}
+
+	sv.initServices[prefix].InitFlags(sv.cmdLine.FlagSet)
+	sv.flagsInitialized[prefix] = true
+
+	return sv.cmdLine.Reparse()
 }
 
 // Run service and its components at the same time
@@ -178,30 +307,33 @@ func (sv *service) run() <-chan error {
 
 	// Start all services
 	for _, subService := range sv.subServices {
-		go func(subSv Runnable) { c <- subSv.Run() }(subService)
+		sv.setState(subService.Name(), stateRunning, nil)
+
+		go func(subSv Runnable) {
+			err := subSv.Run()
+			if err != nil {
+				sv.setState(subSv.Name(), stateFailed, err)
+			}
+			c <- err
+		}(subService)
 	}
 
 	return c
 }
 
-// Stop service and stop its components at the same time
+// Stop service in phases, see GracefulStopper.
+// Only the first call stops the service, it also makes Start return
 func (sv *service) Stop() {
-	sv.logger.Infoln("Stopping service...")
-	stopChan := make(chan bool)
-	for _, subService := range sv.subServices {
-		go func(subSv Runnable) { stopChan <- <-subSv.Stop() }(subService)
-	}
-
-	for _, dbSv := range sv.initServices {
-		go func(subSv Runnable) { stopChan <- <-subSv.Stop() }(dbSv)
-	}
-
-	for i := 0; i < len(sv.subServices)+len(sv.initServices); i++ {
-		<-stopChan
-	}
+	_ = sv.shutdown()
+}
 
-	//s.stopFunc()
-	sv.logger.Infoln("service stopped")
+// shutdown stops the service once and returns the error of stopping it
+func (sv *service) shutdown() error {
+	sv.stopOnce.Do(func() {
+		sv.stopErr = sv.stop()
+		close(sv.stopped)
+	})
+	return sv.stopErr
 }
 
 func (sv *service) RunFunction(fn Function) error {
@@ -217,26 +349,25 @@ func (sv *service) Logger(prefix string) logger.Logger {
 }
 
 func (sv *service) OutEnv() {
+	if sv.outEnvFormat == "yaml" {
+		sv.cmdLine.GetSampleYAML()
+		return
+	}
 	sv.cmdLine.GetSampleEnvs()
 }
 
+func (sv *service) Flags() *AppFlagSet {
+	return sv.cmdLine
+}
+
 func (sv *service) parseFlags() {
-	envFile := os.Getenv("ENV_FILE")
-	if envFile == "" {
-		envFile = ".env"
+	if err := sv.loadEnvFile(); err != nil {
+		sv.logger.Fatalln(err)
 	}
 
-	_, err := os.Stat(envFile)
-	if err == nil {
-		err := godotenv.Load(envFile)
-		if err != nil {
-			sv.logger.Fatalf("Loading env(%s): %s", envFile, err.Error())
-		}
-	} else if envFile != ".env" {
-		sv.logger.Fatalf("Loading env(%s): %s", envFile, err.Error())
+	if err := sv.cmdLine.Parse(sv.args); err != nil {
+		sv.logger.Fatalln(err)
 	}
-
-	sv.cmdLine.Parse([]string{})
 }
 
 // WithName Service must have a name for service discovery and logging/monitoring
@@ -267,17 +398,52 @@ func WithRunnable(r Runnable) Option {
 }
 
 // Add init component to SDK
-// These components will run sequentially before service run
+// These components will run sequentially before service run,
+// in registration order unless dependencies are declared (see WithDependencies)
 func WithInitRunnable(r PrefixRunnable) Option {
 	return func(s *service) {
+		if _, ok := s.overrides[r.GetPrefix()]; ok {
+			return
+		}
+
 		if _, ok := s.initServices[r.GetPrefix()]; ok {
 			log.Fatal(fmt.Sprintf("prefix %s is duplicated", r.GetPrefix()))
 		}
 
 		s.initServices[r.GetPrefix()] = r
+		s.initOrder = append(s.initOrder, r.GetPrefix())
+	}
+}
+
+// WithOverride replaces the init component with the same prefix,
+// whether it is added before or after this option. Ex: a fake in tests.
+// The component is added if no one has its prefix
+func WithOverride(r PrefixRunnable) Option {
+	return func(s *service) {
+		if _, ok := s.initServices[r.GetPrefix()]; !ok {
+			s.initOrder = append(s.initOrder, r.GetPrefix())
+		}
+
+		s.initServices[r.GetPrefix()] = r
+		s.overrides[r.GetPrefix()] = r
+	}
+}
+
+// WithOptions groups options, they are applied in order
+func WithOptions(opts ...Option) Option {
+	return func(s *service) {
+		for _, opt := range opts {
+			opt(s)
+		}
 	}
 }
 
+// WithArgs sets command line arguments parsed by New. Ex: "-gin-mode=release".
+// They win over env variables and config files
+func WithArgs(args ...string) Option {
+	return func(s *service) { s.args = append(s.args, args...) }
+}
+
 func (sv *service) Get(prefix string) (interface{}, bool) {
 	is, ok := sv.initServices[prefix]
 
@@ -0,0 +1,180 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+// Package servicetest runs a goservice.Service in-process for end to end tests.
+//
+//	sv := servicetest.New(t,
+//		goservice.WithName("demo"),
+//		goservice.WithInitRunnable(sdkgorm.NewGormDB("main", "")),
+//		goservice.WithInitRunnable(natspb.NewNatsPubSub("nats", "")),
+//		servicetest.SQLite("main", ""),
+//		servicetest.LocalPubSub("nats"),
+//		servicetest.Config("app-env", "stg"),
+//	)
+//	sv.HTTPServer().AddHandler(routes)
+//	sv.Start()
+//	resp, err := sv.Client.Get("/ping")
+package servicetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goservice "github.com/lequocbinh04/go-sdk"
+	"github.com/lequocbinh04/go-sdk/httpserver"
+	"github.com/lequocbinh04/go-sdk/plugin/pubsub/localpb"
+	"github.com/lequocbinh04/go-sdk/plugin/storage/sdkgorm"
+)
+
+const defaultTimeout = 10 * time.Second
+
+var sqliteSeq uint64
+
+// Service is a service under test, BaseURL and Client are set by Start
+type Service struct {
+	goservice.Service
+	// BaseURL of the HTTP server. Ex: http://127.0.0.1:53412
+	BaseURL string
+	// Client sends requests with a relative URL (Ex: "/healthz") to the HTTP server
+	Client *http.Client
+
+	t testing.TB
+}
+
+// Config sets flag name to value, it wins over env variables and config files
+func Config(name, value string) goservice.Option {
+	return goservice.WithArgs(fmt.Sprintf("-%s=%s", name, value))
+}
+
+// Override replaces the init component with the same prefix by r
+func Override(r goservice.PrefixRunnable) goservice.Option {
+	return goservice.WithOverride(r)
+}
+
+// LocalPubSub replaces the pubsub with prefix (Ex: natspb) by an in-memory one
+func LocalPubSub(prefix string) goservice.Option {
+	return goservice.WithOverride(localpb.NewPubsub(prefix))
+}
+
+// SQLite replaces the gorm database with prefix by an in-memory sqlite database.
+// Every call has its own database
+func SQLite(name, prefix string) goservice.Option {
+	flagPrefix := prefix
+	if prefix != "" {
+		flagPrefix += "-"
+	}
+
+	uri := fmt.Sprintf("file:servicetest-%d?mode=memory&cache=shared", atomic.AddUint64(&sqliteSeq, 1))
+
+	return goservice.WithOptions(
+		goservice.WithOverride(sdkgorm.NewGormDB(name, prefix)),
+		Config(flagPrefix+"gorm-db-type", "sqlite"),
+		Config(flagPrefix+"gorm-db-uri", uri),
+	)
+}
+
+// New creates the service with opts and runs its init components.
+// Handlers can be added to its HTTP server before calling Start.
+// The service is stopped when the test ends, even if it is not started
+func New(t testing.TB, opts ...goservice.Option) *Service {
+	t.Helper()
+
+	opts = append([]goservice.Option{Config("ginPort", "0")}, opts...)
+	sv := goservice.New(opts...)
+
+	if err := sv.Init(); err != nil {
+		t.Fatalf("servicetest: init service: %v", err)
+	}
+
+	// runs after the cleanup of Start, Stop does nothing then
+	t.Cleanup(sv.Stop)
+
+	return &Service{Service: sv, t: t}
+}
+
+// Start is New then Start of the service
+func Start(t testing.TB, opts ...goservice.Option) *Service {
+	t.Helper()
+
+	s := New(t, opts...)
+	s.Start()
+	return s
+}
+
+// Start starts the service with its HTTP server on a random port
+// and waits until OnReady hooks ran. The service is stopped when the test ends
+func (s *Service) Start() {
+	s.t.Helper()
+
+	ginService, ok := s.HTTPServer().(httpserver.GinService)
+	if !ok {
+		s.t.Fatalf("servicetest: HTTP server %T has no port", s.HTTPServer())
+	}
+
+	// the HTTP server only runs if it has handlers
+	s.HTTPServer().AddHandler(func(*gin.Engine) {})
+
+	// runs after the hooks added so far
+	ready := make(chan struct{})
+	s.Add(goservice.WithOnReady(func(goservice.ServiceContext) error {
+		close(ready)
+		return nil
+	}))
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- s.Service.Start(nil) }()
+
+	select {
+	case <-ready:
+	case err := <-errChan:
+		s.t.Fatalf("servicetest: start service: %v", err)
+	case <-time.After(defaultTimeout):
+		s.Stop()
+		s.t.Fatalf("servicetest: service is not ready after %s", defaultTimeout)
+	}
+
+	s.t.Cleanup(func() {
+		s.Stop()
+		if err := <-errChan; err != nil {
+			s.t.Errorf("servicetest: service stopped: %v", err)
+		}
+	})
+
+	s.BaseURL = fmt.Sprintf("http://127.0.0.1:%d", ginService.Port())
+	s.Client = &http.Client{
+		Transport: &baseURLTransport{baseURL: s.BaseURL, next: http.DefaultTransport},
+		Timeout:   defaultTimeout,
+	}
+}
+
+// URL returns the absolute URL of path on the HTTP server
+func (s *Service) URL(path string) string {
+	return s.BaseURL + path
+}
+
+// baseURLTransport sends requests with a relative URL to baseURL
+type baseURLTransport struct {
+	baseURL string
+	next    http.RoundTripper
+}
+
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	u, err := url.Parse(t.baseURL + req.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL = u
+	req.Host = u.Host
+
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,80 @@
+package servicetest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	goservice "github.com/lequocbinh04/go-sdk"
+	pb "github.com/lequocbinh04/go-sdk/plugin/pubsub"
+	"github.com/lequocbinh04/go-sdk/plugin/pubsub/natspb"
+	"github.com/lequocbinh04/go-sdk/plugin/storage/sdkgorm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNew(t *testing.T) {
+	sv := New(t,
+		goservice.WithName("demo"),
+		goservice.WithInitRunnable(sdkgorm.NewGormDB("main", "")),
+		goservice.WithInitRunnable(natspb.NewNatsPubSub("nats", "")),
+		SQLite("main", ""),
+		LocalPubSub("nats"),
+		Config("app-env", goservice.StgEnv),
+	)
+
+	sv.HTTPServer().AddHandler(func(engine *gin.Engine) {
+		engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, sv.Env()) })
+	})
+	sv.Start()
+
+	assert.Equal(t, goservice.StgEnv, sv.Env())
+
+	db, err := goservice.Get[*gorm.DB](sv, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", db.Dialector.Name())
+
+	_, err = goservice.Get[pb.Provider](sv, "nats")
+	assert.NoError(t, err)
+
+	resp, err := sv.Client.Get("/ping")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, goservice.StgEnv, string(body))
+	}
+
+	resp, err = sv.Client.Get("/readyz")
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestStartTwice(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		sv := Start(t, goservice.WithName("demo"))
+
+		resp, err := http.Get(sv.URL("/livez"))
+		if assert.NoError(t, err) {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		}
+	}
+}
+
+func TestNewStopsService(t *testing.T) {
+	var stopped bool
+
+	t.Run("new", func(t *testing.T) {
+		New(t, goservice.WithName("demo"), goservice.WithOnStop(func(goservice.ServiceContext) error {
+			stopped = true
+			return nil
+		}))
+		assert.False(t, stopped)
+	})
+
+	assert.True(t, stopped)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2019, Viet Tran, 200Lab Team.
+
+package goservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	shutdownTimeoutFlag    = "shutdown-timeout"
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// GracefulStopper is an optional interface for Runnable components serving
+// connections or messages. When the service stops, it calls StopAccepting of all
+// components, then Drain of all components, then Stop of all components.
+// All calls share the deadline of the shutdown timeout.
+type GracefulStopper interface {
+	// StopAccepting stops accepting new connections or messages, it must not
+	// wait for in-flight ones
+	StopAccepting(ctx context.Context) error
+	// Drain waits until in-flight requests or messages are done or ctx is done
+	Drain(ctx context.Context) error
+}
+
+// ShutdownTimeoutError is returned by Start when components did not stop
+// before the shutdown timeout
+type ShutdownTimeoutError struct {
+	Components []string
+}
+
+func (e *ShutdownTimeoutError) Error() string {
+	return fmt.Sprintf("components did not stop in time: %s", strings.Join(e.Components, ", "))
+}
+
+type namedRunnable struct {
+	name string
+	Runnable
+}
+
+// stopOrder returns components in the order they are stopped: the sub services,
+// then init components in reverse dependency order. Init components are named
+// by their prefix, the others by their name
+func (sv *service) stopOrder() (subServices, initServices []namedRunnable) {
+	for _, subService := range sv.subServices {
+		subServices = append(subServices, namedRunnable{name: subService.Name(), Runnable: subService})
+	}
+
+	prefixes, err := sv.sortedInitPrefixes()
+	if err != nil {
+		sv.logger.Errorln("cannot order init components, stop them in reverse registration order:", err)
+		prefixes = sv.initOrder
+	}
+
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		initServices = append(initServices, namedRunnable{name: prefixes[i], Runnable: sv.initServices[prefixes[i]]})
+	}
+
+	return subServices, initServices
+}
+
+// stop stops the service in phases within the shutdown timeout:
+// deregister the service, run OnStop hooks, stop accepting connections and messages, drain in-flight ones,
+// stop sub services at the same time then init components one by one
+func (sv *service) stop() error {
+	sv.logger.Infoln("Stopping service...")
+	sv.setReady(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), sv.shutdownTimeout)
+	defer cancel()
+
+	// deregister first, so no new traffic is sent to the service
+	if sv.stopFunc != nil {
+		sv.stopFunc()
+	}
+
+	hookErr := sv.runStopHooks()
+
+	subServices, initServices := sv.stopOrder()
+	all := append(append([]namedRunnable{}, subServices...), initServices...)
+
+	var graceful []namedRunnable
+	for _, c := range all {
+		if _, ok := unwrapComponent(c.Runnable).(GracefulStopper); ok {
+			graceful = append(graceful, c)
+		}
+	}
+
+	for _, c := range graceful {
+		if err := unwrapComponent(c.Runnable).(GracefulStopper).StopAccepting(ctx); err != nil {
+			sv.logger.Errorf("%s cannot stop accepting: %s", c.name, err.Error())
+		}
+	}
+
+	timedOut := sv.waitAll(ctx, graceful, func(c namedRunnable) <-chan bool {
+		done := make(chan bool, 1)
+		go func() {
+			if err := unwrapComponent(c.Runnable).(GracefulStopper).Drain(ctx); err != nil {
+				sv.logger.Errorf("%s cannot drain: %s", c.name, err.Error())
+			}
+			done <- true
+		}()
+		return done
+	})
+
+	timedOut = append(timedOut, sv.waitAll(ctx, subServices, sv.stopComponent)...)
+
+	for _, c := range initServices {
+		timedOut = append(timedOut, sv.waitAll(ctx, []namedRunnable{c}, sv.stopComponent)...)
+	}
+
+	if len(timedOut) > 0 {
+		err := &ShutdownTimeoutError{Components: uniqueNames(timedOut)}
+		sv.logger.Errorln(err.Error())
+		return err
+	}
+
+	sv.logger.Infoln("service stopped")
+	return hookErr
+}
+
+// StopPrefix stops init components with prefix, other components are not stopped
+func (sv *service) StopPrefix(prefix ...string) error {
+	requested := make(map[string]bool, len(prefix))
+	for _, pre := range prefix {
+		if _, ok := sv.initServices[pre]; !ok {
+			return fmt.Errorf("component %q is not registered", pre)
+		}
+		requested[pre] = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sv.shutdownTimeout)
+	defer cancel()
+
+	var timedOut []string
+
+	_, initServices := sv.stopOrder()
+	for _, c := range initServices {
+		if requested[c.name] {
+			timedOut = append(timedOut, sv.waitAll(ctx, []namedRunnable{c}, sv.stopComponent)...)
+		}
+	}
+
+	if len(timedOut) > 0 {
+		return &ShutdownTimeoutError{Components: timedOut}
+	}
+	return nil
+}
+
+// waitAll runs fn for all components at the same time and waits until they are done
+// or ctx is done. It returns names of components which are not done
+func (sv *service) waitAll(ctx context.Context, components []namedRunnable, fn func(namedRunnable) <-chan bool) []string {
+	var (
+		timedOut []string
+		locker   sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(len(components))
+	for _, c := range components {
+		go func(c namedRunnable) {
+			defer wg.Done()
+
+			done := fn(c)
+
+			// components done when the deadline is reached are not timed out
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				locker.Lock()
+				timedOut = append(timedOut, c.name)
+				locker.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return timedOut
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := names[:0]
+
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+
+	return unique
+}
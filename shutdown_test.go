@@ -0,0 +1,97 @@
+package goservice
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownRecorder struct {
+	sync.Mutex
+	calls []string
+}
+
+func (r *shutdownRecorder) record(call string) {
+	r.Lock()
+	r.calls = append(r.calls, call)
+	r.Unlock()
+}
+
+type gracefulComponent struct {
+	testComponent
+	recorder *shutdownRecorder
+	// Drain blocks until ctx is done
+	stuck bool
+}
+
+func (c *gracefulComponent) StopAccepting(ctx context.Context) error {
+	c.recorder.record("accept:" + c.prefix)
+	return nil
+}
+
+func (c *gracefulComponent) Drain(ctx context.Context) error {
+	if c.stuck {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	c.recorder.record("drain:" + c.prefix)
+	return nil
+}
+
+func (c *gracefulComponent) Stop() <-chan bool {
+	c.recorder.record("stop:" + c.prefix)
+	return c.testComponent.Stop()
+}
+
+func TestStopPhases(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	sv := newTestService(
+		WithRunnable(&gracefulComponent{testComponent: testComponent{prefix: "gin"}, recorder: recorder}),
+		WithInitRunnable(&gracefulComponent{testComponent: testComponent{prefix: "gorm"}, recorder: recorder}),
+		WithInitRunnable(&gracefulComponent{
+			testComponent: testComponent{prefix: "nats", dependsOn: []string{"gorm"}}, recorder: recorder,
+		}),
+	)
+
+	assert.NoError(t, sv.shutdown())
+
+	assert.ElementsMatch(t, []string{"accept:gin", "accept:nats", "accept:gorm"}, recorder.calls[:3])
+	assert.ElementsMatch(t, []string{"drain:gin", "drain:nats", "drain:gorm"}, recorder.calls[3:6])
+	assert.Equal(t, []string{"stop:gin", "stop:nats", "stop:gorm"}, recorder.calls[6:])
+}
+
+func TestStopTimeout(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	sv := newTestService(
+		WithInitRunnable(&gracefulComponent{testComponent: testComponent{prefix: "gorm"}, recorder: recorder}),
+		WithInitRunnable(&gracefulComponent{
+			testComponent: testComponent{prefix: "nats"}, recorder: recorder, stuck: true,
+		}),
+	)
+	sv.shutdownTimeout = 50 * time.Millisecond
+
+	err := sv.shutdown()
+	if assert.IsType(t, &ShutdownTimeoutError{}, err) {
+		assert.Equal(t, []string{"nats"}, err.(*ShutdownTimeoutError).Components)
+	}
+
+	// Stop is called once
+	assert.Equal(t, err, sv.shutdown())
+}
+
+func TestStopPrefix(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	sv := newTestService(
+		WithInitRunnable(&gracefulComponent{testComponent: testComponent{prefix: "gorm"}, recorder: recorder}),
+		WithInitRunnable(&gracefulComponent{testComponent: testComponent{prefix: "nats"}, recorder: recorder}),
+	)
+
+	assert.NoError(t, sv.StopPrefix("gorm"))
+	assert.NoError(t, sv.StopPrefix("gorm"))
+	assert.Equal(t, []string{"stop:gorm", "stop:gorm"}, recorder.calls)
+
+	assert.Error(t, sv.StopPrefix("redis"))
+}
@@ -77,7 +77,19 @@ func (as *asyncJob) Retry(ctx context.Context) error {
 	as.retryIndex += 1
 
 	as.log("prepare to retry job: "+as.name+" after", as.retryDurations[as.retryIndex])
-	time.Sleep(as.retryDurations[as.retryIndex])
+
+	// stop waiting if the job is canceled. Ex: the service is stopping
+	timer := time.NewTimer(as.retryDurations[as.retryIndex])
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		as.state = Failed
+		as.log("canceled job: " + as.name)
+		return ctx.Err()
+	}
+
 	as.log("retrying job: " + as.name)
 
 	return as.Execute(ctx)
@@ -41,7 +41,7 @@ func (ag *asyncGroup) Run(ctx context.Context) error {
 			go func(as Job) {
 				defer func() {
 					if err := recover(); err != nil {
-						j := t.(*asyncJob)
+						j := as.(*asyncJob)
 						ag.logger.Error(j.name, err)
 
 						err = errors.New(fmt.Sprintf("%v", err))
@@ -69,7 +69,7 @@ func (ag *asyncGroup) do(ctx context.Context, as Job) error {
 	if err := as.Execute(ctx); err != nil {
 		for {
 			if err := as.Retry(ctx); err != nil {
-				if err == ErrTaskFailed {
+				if err == ErrTaskFailed || ctx.Err() != nil {
 					return err
 				}
 				continue
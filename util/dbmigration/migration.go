@@ -66,28 +66,37 @@ func (sql *sqlMigration) actualVersion() int {
 	return sql.actVersion
 }
 
-func (sql *sqlMigration) Migrate() error {
+// versions returns versions of up migration files in ascending order
+func (sql *sqlMigration) versions() ([]int, error) {
 	files, err := ioutil.ReadDir(sql.sqlFolder)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var versions []int
-	maxVersion := 0
 
 	for _, f := range files {
 		if filepath.Ext(f.Name()) == ".sql" {
 			if n, _ := strconv.Atoi(strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))); n > 0 {
 				versions = append(versions, n)
-
-				if maxVersion < n {
-					maxVersion = n
-				}
 			}
 		}
 	}
 
 	sort.Ints(versions)
+	return versions, nil
+}
+
+func (sql *sqlMigration) Migrate() error {
+	versions, err := sql.versions()
+	if err != nil {
+		return err
+	}
+
+	maxVersion := 0
+	if len(versions) > 0 {
+		maxVersion = versions[len(versions)-1]
+	}
 
 	actualVer := sql.actualVersion()
 	if actualVer < maxVersion && len(versions) > 0 {
@@ -151,6 +160,94 @@ func (sql *sqlMigration) Migrate() error {
 	return nil
 }
 
+// Status of migrations in the database
+type Status struct {
+	Current int   `json:"current"`
+	Latest  int   `json:"latest"`
+	Pending []int `json:"pending"`
+}
+
+// Status returns the current version and versions not migrated yet
+func (sql *sqlMigration) Status() (Status, error) {
+	versions, err := sql.versions()
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Current: sql.actualVersion()}
+	for _, v := range versions {
+		if v > status.Current {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+
+	if len(versions) > 0 {
+		status.Latest = versions[len(versions)-1]
+	}
+
+	return status, nil
+}
+
+// Down rolls back the last steps migrated versions with their down files, in the reverse
+// order of Migrate: <version>.<env>.down.sql if it exists then <version>.down.sql
+func (sql *sqlMigration) Down(steps int) error {
+	versions, err := sql.versions()
+	if err != nil {
+		return err
+	}
+
+	actualVer := sql.actualVersion()
+
+	var applied []int
+	for _, v := range versions {
+		if v <= actualVer {
+			applied = append(applied, v)
+		}
+	}
+
+	for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+		v := applied[i]
+
+		sqlData, err := ioutil.ReadFile(filepath.Join(sql.sqlFolder, fmt.Sprintf("%d.down.sql", v)))
+		if err != nil {
+			return fmt.Errorf("no down migration of version %d: %w", v, err)
+		}
+
+		sqlSubData, _ := ioutil.ReadFile(filepath.Join(sql.sqlFolder, fmt.Sprintf("%d.%s.down.sql", v, sql.env)))
+
+		sql.logger.Infoln("rolling back sql db... version:", v)
+
+		prevVersion := 0
+		if i > 0 {
+			prevVersion = applied[i-1]
+		}
+
+		err = sql.db.Transaction(func(tx *gorm.DB) error {
+			// the env file is applied after the base one, so it is rolled back first
+			for _, command := range append(strings.Split(string(sqlSubData), ";"), strings.Split(string(sqlData), ";")...) {
+				if strings.TrimSpace(command) == "" {
+					continue
+				}
+
+				if err := tx.Exec(command).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Table(sql.getTableName()).
+				Where("name = ?", "DB_VERSION").
+				Updates(map[string]interface{}{"value": prevVersion}).Error
+		})
+
+		if err != nil {
+			return fmt.Errorf("rolling back version %d: %w", v, err)
+		}
+	}
+
+	sql.logger.Infoln("rolling back sql db... done.")
+	return nil
+}
+
 func (sql *sqlMigration) getTableName() string {
 	if sql.tbName == "" {
 		return defaultTbName
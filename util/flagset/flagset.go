@@ -0,0 +1,33 @@
+// Package flagset reads flag values of a reloaded flag set into options of components.
+//
+//	opt := Options{}
+//	next := flag.NewFlagSet("redis", flag.ContinueOnError)
+//	opt.initFlags(next) // same flags as InitFlags
+//	if err := flagset.Copy(next, fs); err != nil {
+//		return err
+//	}
+package flagset
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Copy sets flags of dst to values of the flags with the same name in src,
+// flags missing in src keep their value
+func Copy(dst, src *flag.FlagSet) error {
+	var err error
+
+	dst.VisitAll(func(f *flag.Flag) {
+		from := src.Lookup(f.Name)
+		if err != nil || from == nil {
+			return
+		}
+
+		if setErr := f.Value.Set(from.Value.String()); setErr != nil {
+			err = fmt.Errorf("invalid value %q for flag -%s: %v", from.Value.String(), f.Name, setErr)
+		}
+	})
+
+	return err
+}
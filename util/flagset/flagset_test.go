@@ -0,0 +1,27 @@
+package flagset
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopy(t *testing.T) {
+	src := flag.NewFlagSet("src", flag.ContinueOnError)
+	src.String("uri", "redis://new", "")
+	src.String("max-active", "20", "")
+
+	dst := flag.NewFlagSet("dst", flag.ContinueOnError)
+	uri := dst.String("uri", "redis://old", "")
+	maxActive := dst.Int("max-active", 10, "")
+	maxIdle := dst.Int("max-idle", 5, "")
+
+	assert.NoError(t, Copy(dst, src))
+	assert.Equal(t, "redis://new", *uri)
+	assert.Equal(t, 20, *maxActive)
+	assert.Equal(t, 5, *maxIdle)
+
+	assert.NoError(t, src.Set("max-active", "many"))
+	assert.Error(t, Copy(dst, src))
+}
@@ -0,0 +1,206 @@
+// Package grpcerr converts *sdkcm.AppError to gRPC status and back.
+// The key and HTTP status code of the AppError are sent in an ErrorInfo detail,
+// so a client gets the same AppError as the server returned. Its log, which may carry
+// DB or internal error text, is only sent by ToStatusWithLog, out of prd env.
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain of ErrorInfo details carrying AppError
+const Domain = "go-sdk"
+
+const (
+	metaLog        = "log"
+	metaStatusCode = "status_code"
+
+	// message of errors which are not AppError nor gRPC status, their text is hidden
+	unknownMessage = "something went wrong in the server"
+)
+
+// ToStatus converts err to a gRPC status:
+//   - errors having a gRPC status keep it
+//   - *sdkcm.AppError gets the code matching its HTTP status code and an ErrorInfo detail
+//   - context errors get Canceled or DeadlineExceeded
+//   - others get Unknown with a generic message
+//
+// Log and root errors are not sent to clients, see ToStatusWithLog
+func ToStatus(err error) *status.Status {
+	return toStatus(err, false)
+}
+
+// ToStatusWithLog converts err to a gRPC status like ToStatus, the log of AppError
+// (or its root error) and the text of other errors are sent for debugging.
+// It must not be used in prd env
+func ToStatusWithLog(err error) *status.Status {
+	return toStatus(err, true)
+}
+
+func toStatus(err error, withLog bool) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	var appErr *sdkcm.AppError
+	if errors.As(err, &appErr) {
+		st := status.New(CodeFromHTTPStatus(appErr.StatusCode), appErr.Message)
+
+		metadata := map[string]string{metaStatusCode: strconv.Itoa(appErr.StatusCode)}
+		if withLog {
+			log := appErr.Log
+			if log == "" && appErr.RootErr != nil {
+				log = appErr.RootError().Error()
+			}
+			metadata[metaLog] = log
+		}
+
+		detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   appErr.Key,
+			Domain:   Domain,
+			Metadata: metadata,
+		})
+		if detailErr != nil {
+			return st
+		}
+		return detailed
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+
+	if !withLog {
+		return status.New(codes.Unknown, unknownMessage)
+	}
+	return status.New(codes.Unknown, err.Error())
+}
+
+// ToError returns err as a gRPC status error, see ToStatus
+func ToError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToStatus(err).Err()
+}
+
+// ToErrorWithLog returns err as a gRPC status error, see ToStatusWithLog
+func ToErrorWithLog(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToStatusWithLog(err).Err()
+}
+
+// FromError decodes a gRPC status error to *sdkcm.AppError if it carries one.
+// Other errors are returned as is
+func FromError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != Domain {
+			continue
+		}
+
+		statusCode, convErr := strconv.Atoi(info.Metadata[metaStatusCode])
+		if convErr != nil {
+			statusCode = HTTPStatusFromCode(st.Code())
+		}
+
+		log := info.Metadata[metaLog]
+		root := errors.New(log)
+		if log == "" {
+			root = errors.New(st.Message())
+		}
+
+		return sdkcm.NewErrorResponse(statusCode, root, st.Message(), log, info.Reason)
+	}
+
+	return err
+}
+
+// CodeFromHTTPStatus returns the gRPC code matching an HTTP status code
+func CodeFromHTTPStatus(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if statusCode >= 400 && statusCode < 500 {
+		return codes.InvalidArgument
+	}
+	return codes.Internal
+}
+
+// HTTPStatusFromCode returns the HTTP status code matching a gRPC code
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
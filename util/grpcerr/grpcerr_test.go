@@ -0,0 +1,63 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lequocbinh04/go-sdk/sdkcm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAppErrorRoundTrip(t *testing.T) {
+	appErr := sdkcm.ErrEntityNotFound("User", sdkcm.ErrRecordNotFound)
+
+	err := ToErrorWithLog(appErr)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	decoded := FromError(err)
+	require.IsType(t, &sdkcm.AppError{}, decoded)
+
+	got := decoded.(*sdkcm.AppError)
+	assert.Equal(t, http.StatusBadRequest, got.StatusCode)
+	assert.Equal(t, "user not found", got.Message)
+	assert.Equal(t, "ErrUserNotFound", got.Key)
+	assert.Equal(t, "record not found", got.Log)
+	assert.Equal(t, "record not found", got.Error())
+}
+
+func TestToStatusHidesLog(t *testing.T) {
+	err := ToError(sdkcm.ErrDB(errors.New("dial tcp 10.0.0.1:5432: connection refused")))
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	got := FromError(err).(*sdkcm.AppError)
+	assert.Equal(t, "DB_ERROR", got.Key)
+	assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+	assert.Empty(t, got.Log)
+	assert.NotContains(t, got.Error(), "10.0.0.1")
+
+	plain := ToStatus(errors.New("pq: relation users does not exist"))
+	assert.Equal(t, codes.Unknown, plain.Code())
+	assert.NotContains(t, plain.Message(), "users")
+	assert.Contains(t, ToStatusWithLog(errors.New("pq: relation users does not exist")).Message(), "users")
+}
+
+func TestToStatus(t *testing.T) {
+	assert.Nil(t, ToStatus(nil))
+	assert.Equal(t, codes.NotFound, ToStatus(status.Error(codes.NotFound, "no user")).Code())
+	assert.Equal(t, codes.DeadlineExceeded, ToStatus(context.DeadlineExceeded).Code())
+	assert.Equal(t, codes.Unknown, ToStatus(errors.New("boom")).Code())
+	assert.Equal(t, codes.PermissionDenied, ToStatus(sdkcm.ErrNoPermission(nil)).Code())
+}
+
+func TestFromErrorKeepsOtherErrors(t *testing.T) {
+	err := status.Error(codes.Unavailable, "no connection")
+	assert.Equal(t, err, FromError(err))
+
+	plain := errors.New("boom")
+	assert.Equal(t, plain, FromError(plain))
+}
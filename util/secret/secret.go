@@ -0,0 +1,126 @@
+// Package secret masks credentials before they are printed to logs,
+// sample env files or config dumps.
+package secret
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const Masked = "xxxxx"
+
+var (
+	locker      = new(sync.RWMutex)
+	secretFlags = map[string]bool{}
+
+	// flags with these words in their names are secret even if they are not marked
+	secretWords = []string{"password", "passwd", "secret", "token", "api-key", "apikey", "private-key", "dsn"}
+
+	// query params with these names are masked in URIs
+	secretParams = []string{"password", "passwd", "pwd", "secret", "token", "access_token", "api_key", "apikey", "key"}
+
+	// user:password@tcp(host:3306)/db
+	reDSNPassword = regexp.MustCompile(`^([^:/@\s]*):([^@\s]*)@`)
+	// host=localhost password=secret
+	reKVPassword = regexp.MustCompile(`(?i)\b(password|pwd)=([^\s;&]+)`)
+)
+
+// MarkFlag marks flags as secret, their values are masked everywhere the SDK prints them
+func MarkFlag(names ...string) {
+	locker.Lock()
+	defer locker.Unlock()
+
+	for _, name := range names {
+		secretFlags[name] = true
+	}
+}
+
+// IsSecretFlag returns true if the flag is marked as secret or its name looks like a credential
+func IsSecretFlag(name string) bool {
+	locker.RLock()
+	marked := secretFlags[name]
+	locker.RUnlock()
+
+	if marked {
+		return true
+	}
+
+	name = strings.ToLower(name)
+	for _, w := range secretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask hides a secret value entirely
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return Masked
+}
+
+// MaskFlag masks the value of a flag: secret flags are hidden entirely,
+// credentials inside other values (such as connection-strings) are redacted
+func MaskFlag(name, value string) string {
+	if IsSecretFlag(name) {
+		return Mask(value)
+	}
+	return RedactURI(value)
+}
+
+// RedactURI hides passwords and credential params of connection-strings,
+// it supports URLs, MySQL DSN and key=value DSN. Comma separated lists are redacted item by item
+func RedactURI(uri string) string {
+	if !strings.ContainsAny(uri, "@=") {
+		return uri
+	}
+
+	items := strings.Split(uri, ",")
+	for i := range items {
+		items[i] = redactOne(items[i])
+	}
+	return strings.Join(items, ",")
+}
+
+func redactOne(uri string) string {
+	if strings.Contains(uri, "://") {
+		if u, err := url.Parse(strings.TrimSpace(uri)); err == nil {
+			return redactURL(uri, u)
+		}
+	}
+
+	uri = reDSNPassword.ReplaceAllString(uri, "${1}:"+Masked+"@")
+	return reKVPassword.ReplaceAllString(uri, "${1}="+Masked)
+}
+
+func redactURL(raw string, u *url.URL) string {
+	changed := false
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Masked)
+		changed = true
+	}
+
+	query := u.Query()
+	for key := range query {
+		for _, p := range secretParams {
+			if strings.EqualFold(key, p) {
+				query.Set(key, Masked)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return raw
+	}
+
+	u.RawQuery = query.Encode()
+	return strings.Replace(raw, strings.TrimSpace(raw), u.String(), 1)
+}